@@ -0,0 +1,234 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+const (
+	// dates used in circuits are between 0001-01-01 and 9999-12-31
+	maxYear = 9999
+	// days from 0000-03-01 to 1970-01-01
+	epochDays = 719468
+	// quotients of the integer divisions computing a day number fit in maxYear
+	maxQuotient = maxYear
+	// accrued interest in cents is smaller than 2^64
+	maxInterest = "18446744073709551615"
+)
+
+// divisors used by dayNumber: year/4, year/100, year/400 and the day of year from March
+var dayNumberDivisors = [4]int{4, 100, 400, 5}
+
+// days in each month, February in a common year
+var monthDays = [12]int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+var (
+	errCouponDay    = errors.New("the circuit only supports coupon dates before the 29th of the month")
+	errStepInPeriod = errors.New("the circuit only supports coupon steps on coupon dates")
+)
+
+// DayNumberHint holds the quotients and remainders of the integer divisions needed to
+// compute a day number in a circuit. They are computed by the prover.
+type DayNumberHint struct {
+	Quotients  [4]frontend.Variable
+	Remainders [4]frontend.Variable
+}
+
+// Accrual holds the private values proving the interest accrued on a fixed coupon at a settlement date
+type Accrual struct {
+	PeriodMonths      frontend.Variable    // 12 / coupon frequency
+	PeriodsToMaturity frontend.Variable    // coupon periods from the previous coupon to maturity
+	Previous          [2]frontend.Variable // year and month of the previous coupon date
+	Next              [2]frontend.Variable // year and month of the next coupon date
+	SettlementHint    DayNumberHint
+	PreviousHint      DayNumberHint
+	NextHint          DayNumberHint
+	Interest          frontend.Variable // accrued interest in cents
+	Remainder         frontend.Variable // remainder of the division giving Interest
+}
+
+// mustDivide constrains q and r to be the quotient and remainder of a / b
+func mustDivide(cs *frontend.ConstraintSystem, a frontend.Variable, b int, q, r frontend.Variable) frontend.Variable {
+	cs.AssertIsEqual(a, cs.Add(cs.Mul(q, b), r))
+	cs.AssertIsLessOrEqual(r, b-1)
+	cs.AssertIsLessOrEqual(q, maxQuotient)
+	return q
+}
+
+// dayNumber returns the number of days from 1970-01-01 to year-month-day, a date of the calendar
+// http://howardhinnant.github.io/date_algorithms.html#days_from_civil
+func dayNumber(cs *frontend.ConstraintSystem, curveID ecc.ID, year, month, day frontend.Variable, hint *DayNumberHint) frontend.Variable {
+
+	cs.AssertIsLessOrEqual(year, maxYear)
+	cs.AssertIsLessOrEqual(cs.Sub(month, 1), 11)
+	cs.AssertIsLessOrEqual(cs.Sub(day, 1), 30)
+
+	// years start in March so the leap day is the last day of the year
+	janFeb := cs.Add(cs.IsZero(cs.Sub(month, 1), curveID), cs.IsZero(cs.Sub(month, 2), curveID))
+	y := cs.Sub(year, janFeb)
+	m := cs.Add(cs.Sub(month, 3), cs.Mul(janFeb, 12))
+
+	q4 := mustDivide(cs, y, dayNumberDivisors[0], hint.Quotients[0], hint.Remainders[0])
+	q100 := mustDivide(cs, y, dayNumberDivisors[1], hint.Quotients[1], hint.Remainders[1])
+	q400 := mustDivide(cs, y, dayNumberDivisors[2], hint.Quotients[2], hint.Remainders[2])
+	doy := mustDivide(cs, cs.Add(cs.Mul(m, 153), 2), dayNumberDivisors[3], hint.Quotients[3], hint.Remainders[3])
+
+	// the day exists in the month: in February, y is the year before, so year is a leap year
+	// when y % 4 == 3, unless y % 100 == 99 and y % 400 != 399
+	var lengths [12]frontend.Variable
+	for i, days := range monthDays {
+		lengths[i] = cs.Constant(days)
+	}
+	centuryEnd := cs.IsZero(cs.Sub(hint.Remainders[1], 99), curveID)
+	leap := cs.Mul(cs.IsZero(cs.Sub(hint.Remainders[0], 3), curveID),
+		cs.Sub(1, cs.Mul(centuryEnd, cs.Sub(1, cs.IsZero(cs.Sub(hint.Remainders[2], 399), curveID)))))
+	length := cs.Add(selectByIndex(cs, curveID, cs.Sub(month, 1), lengths[:]), cs.Mul(cs.IsZero(cs.Sub(month, 2), curveID), leap))
+	cs.AssertIsLessOrEqual(day, length)
+
+	days := cs.Add(cs.Mul(y, 365), q4, q400, doy, day)
+	return cs.Sub(days, cs.Add(q100, 1+epochDays))
+}
+
+// monthIndex counts the months from year 0
+func monthIndex(cs *frontend.ConstraintSystem, year, month frontend.Variable) frontend.Variable {
+	return cs.Add(cs.Mul(year, 12), cs.Sub(month, 1))
+}
+
+// mustBeCouponMonth checks year and month are the month of the coupon date periods coupon periods
+// of periodMonths months before the maturity of bond, encoded as in Bond.Fields
+func mustBeCouponMonth(cs *frontend.ConstraintSystem, bond [bondFieldsSize]frontend.Variable,
+	year, month, periodMonths, periods frontend.Variable) {
+
+	frequency, maturityYear, maturityMonth := bond[5], bond[7], bond[8]
+	cs.AssertIsEqual(cs.Mul(periodMonths, frequency), 12)
+	cs.AssertIsLessOrEqual(periodMonths, 12)
+
+	maturity := monthIndex(cs, maturityYear, maturityMonth)
+	cs.AssertIsLessOrEqual(periods, maxYear*12)
+	cs.AssertIsEqual(cs.Sub(maturity, monthIndex(cs, year, month)), cs.Mul(periods, periodMonths))
+}
+
+// mustAccrue proves the interest accrued at the settlement date (year, month, day) on a bond
+// encoded as in Bond.Fields, and returns it in cents. rate is the coupon rate of the period.
+// Coupon dates are the maturity day, every PeriodMonths months before maturity.
+func (a *Accrual) mustAccrue(cs *frontend.ConstraintSystem, curveID ecc.ID,
+	bond [bondFieldsSize]frontend.Variable, rate frontend.Variable, settlement [3]frontend.Variable) frontend.Variable {
+
+	size, frequency, dayCount := bond[1], bond[5], bond[6]
+	couponDay := bond[9]
+
+	// the coupon day exists in every month
+	cs.AssertIsLessOrEqual(cs.Sub(couponDay, 1), 27)
+
+	// previous and next are consecutive coupon dates
+	mustBeCouponMonth(cs, bond, a.Previous[0], a.Previous[1], a.PeriodMonths, a.PeriodsToMaturity)
+	previous := monthIndex(cs, a.Previous[0], a.Previous[1])
+	cs.AssertIsEqual(monthIndex(cs, a.Next[0], a.Next[1]), cs.Add(previous, a.PeriodMonths))
+
+	// previous <= settlement < next
+	settlementDays := dayNumber(cs, curveID, settlement[0], settlement[1], settlement[2], &a.SettlementHint)
+	previousDays := dayNumber(cs, curveID, a.Previous[0], a.Previous[1], couponDay, &a.PreviousHint)
+	nextDays := dayNumber(cs, curveID, a.Next[0], a.Next[1], couponDay, &a.NextHint)
+	cs.AssertIsLessOrEqual(previousDays, settlementDays)
+	cs.AssertIsLessOrEqual(cs.Add(settlementDays, 1), nextDays)
+
+	isThirty := cs.IsZero(dayCount, curveID)
+	isActual360 := cs.IsZero(cs.Sub(dayCount, int(bondmath.Actual360)), curveID)
+	isActualActual := cs.IsZero(cs.Sub(dayCount, int(bondmath.ActualActual)), curveID)
+	cs.AssertIsEqual(cs.Add(isThirty, isActual360, isActualActual), 1)
+
+	// the coupon day is before the 29th so the 30/360 adjustments never apply
+	thirty := cs.Add(cs.Mul(cs.Sub(settlement[0], a.Previous[0]), 360),
+		cs.Mul(cs.Sub(settlement[1], a.Previous[1]), 30),
+		cs.Sub(settlement[2], couponDay))
+	actual := cs.Sub(settlementDays, previousDays)
+	num := cs.Select(isThirty, thirty, actual)
+	den := cs.Select(isActualActual, cs.Mul(frequency, cs.Sub(nextDays, previousDays)), 360)
+
+	// Interest = size * rate * num / (10^rateDecimals * den), rounded down to the cent
+	divisor := cs.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(rateDecimals), nil))
+	cs.AssertIsEqual(cs.Mul(size, rate, num), cs.Add(cs.Mul(a.Interest, divisor), a.Remainder))
+	cs.AssertIsLessOrEqual(a.Remainder, cs.Sub(divisor, 1))
+	cs.AssertIsLessOrEqual(a.Interest, maxInterest)
+
+	return a.Interest
+}
+
+// Assign sets the witness values proving the accrued interest on bond at settle.
+// It returns the accrued interest in cents.
+func (a *Accrual) Assign(bond *Bond, settle time.Time) (*big.Int, error) {
+	coupon := &bond.Coupon
+
+	fields, err := bond.Fields()
+	if err != nil {
+		return nil, err
+	}
+	if coupon.Maturity.Day() > 28 {
+		return nil, errCouponDay
+	}
+	periodMonths, err := coupon.PeriodMonths()
+	if err != nil {
+		return nil, err
+	}
+	previous, next, periods, err := coupon.Period(settle)
+	if err != nil {
+		return nil, err
+	}
+
+	a.PeriodMonths.Assign(periodMonths)
+	a.PeriodsToMaturity.Assign(periods)
+	a.Previous[0].Assign(previous.Year())
+	a.Previous[1].Assign(int(previous.Month()))
+	a.Next[0].Assign(next.Year())
+	a.Next[1].Assign(int(next.Month()))
+	a.SettlementHint.Assign(settle)
+	a.PreviousHint.Assign(previous)
+	a.NextHint.Assign(next)
+
+	// the circuit reads the step-up rate at the settlement date
+	rate := coupon.RateOn(settle)
+	if !rate.Equal(coupon.RateOn(previous)) {
+		return nil, errStepInPeriod
+	}
+	rate = rate.Shift(rateDecimals)
+
+	num, den := coupon.DayCount.Accrual(previous, settle, next, coupon.Frequency)
+
+	var product, divisor, interest, remainder big.Int
+	product.Mul(fields[1], rate.BigInt()).Mul(&product, big.NewInt(num))
+	divisor.Exp(big.NewInt(10), big.NewInt(rateDecimals), nil).Mul(&divisor, big.NewInt(den))
+	interest.QuoRem(&product, &divisor, &remainder)
+
+	a.Interest.Assign(&interest)
+	a.Remainder.Assign(&remainder)
+
+	return &interest, nil
+}
+
+// Assign sets the quotients and remainders used by dayNumber for date
+func (hint *DayNumberHint) Assign(date time.Time) {
+	y, month := date.Year(), date.Month()
+	m := int(month) - 3
+	if month <= 2 {
+		y--
+		m += 12
+	}
+	dividends := [4]int{y, y, y, 153*m + 2}
+	for i, divisor := range dayNumberDivisors {
+		hint.Quotients[i].Assign(dividends[i] / divisor)
+		hint.Remainders[i].Assign(dividends[i] % divisor)
+	}
+}
+
+// assignDate sets a date as year, month, day
+func assignDate(date *[3]frontend.Variable, t time.Time) {
+	date[0].Assign(t.Year())
+	date[1].Assign(int(t.Month()))
+	date[2].Assign(t.Day())
+}
@@ -0,0 +1,162 @@
+package financial
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+type accrualCircuit struct {
+	Bond           [bondFieldsSize]frontend.Variable `gnark:",private"`
+	SettlementDate [3]frontend.Variable              `gnark:",public"`
+	Interest       frontend.Variable                 `gnark:",public"`
+	Accrual        Accrual                           `gnark:",private"`
+}
+
+func (circuit *accrualCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	interest := circuit.Accrual.mustAccrue(cs, curveID, circuit.Bond, circuit.Bond[4], circuit.SettlementDate)
+	cs.AssertIsEqual(circuit.Interest, interest)
+	return nil
+}
+
+func accrualWitness(t *testing.T, bond *Bond, settle time.Time) (accrualCircuit, *big.Int) {
+	var witness accrualCircuit
+	fields, err := bond.Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fields {
+		witness.Bond[i].Assign(fields[i])
+	}
+	assignDate(&witness.SettlementDate, settle)
+	interest, err := witness.Accrual.Assign(bond, settle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return witness, interest
+}
+
+func TestAccrualCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit accrualCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	bonds := []*Bond{
+		{Isin: "CA29250NAT24", Size: "550000", Ticker: "ENB 5.375 27-Sep-2027",
+			Coupon: bondmath.Coupon{Rate: decimal.RequireFromString("5.375"), Frequency: 2, DayCount: bondmath.Thirty360,
+				Maturity: time.Date(2027, 9, 27, 0, 0, 0, 0, time.UTC)}},
+		{Isin: "US89114QCR74", Size: "600000", Ticker: "The Toronto-Dominion VAR 03/04/2024",
+			Coupon: bondmath.Coupon{Rate: decimal.RequireFromString("0.5125"), Frequency: 4, DayCount: bondmath.Actual360,
+				Maturity: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)}},
+		{Isin: "US46625HKC33", Size: "625000", Ticker: "JPM 3.125% 01/23/2025 Callable",
+			Coupon: bondmath.Coupon{Rate: decimal.RequireFromString("3.125"), Frequency: 2, DayCount: bondmath.ActualActual,
+				Maturity: time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC)}},
+		{Isin: "XS1234567896", Size: "200000", Ticker: "PERP 4% Perpetual", Type: Perpetual,
+			Coupon: bondmath.Coupon{Rate: decimal.RequireFromString("4"), Frequency: 4, DayCount: bondmath.Thirty360,
+				Maturity: bondmath.PerpetualMaturity(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC))}},
+	}
+	settlements := []time.Time{
+		time.Date(2021, 11, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 7, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	for _, bond := range bonds {
+		for _, settle := range settlements {
+			witness, interest := accrualWitness(t, bond, settle)
+
+			// same value as the bond math package, rounded down to the cent
+			size, _ := decimal.NewFromString(bond.Size)
+			accrued, err := bond.Coupon.AccruedInterest(size, settle)
+			assert.NoError(err)
+			assert.Equal(accrued.Shift(2).Truncate(0).String(), interest.String(), bond.Ticker, settle)
+
+			witness.Interest.Assign(interest)
+			assert.SolvingSucceeded(r1cs, &witness)
+
+			witness.Interest = frontend.Variable{}
+			witness.Interest.Assign(new(big.Int).Add(interest, big.NewInt(1)))
+			assert.SolvingFailed(r1cs, &witness)
+		}
+	}
+
+	// the initiator can't pick a coupon period that doesn't contain the settlement date
+	settle := settlements[0]
+	witness, interest := accrualWitness(t, bonds[0], settle)
+	witness.Interest.Assign(interest)
+	witness.SettlementDate = [3]frontend.Variable{}
+	assignDate(&witness.SettlementDate, settle.AddDate(0, 6, 0))
+	assert.SolvingFailed(r1cs, &witness)
+
+	// nor one that isn't on the bond schedule
+	shifted := *bonds[0]
+	shifted.Coupon.Maturity = shifted.Coupon.Maturity.AddDate(0, 1, 0)
+	witness, interest = accrualWitness(t, &shifted, settle)
+	witness.Interest.Assign(interest)
+	fields, _ := bonds[0].Fields()
+	for i := range fields {
+		witness.Bond[i] = frontend.Variable{}
+		witness.Bond[i].Assign(fields[i])
+	}
+	assert.SolvingFailed(r1cs, &witness)
+
+	// coupon dates must exist in every month
+	bond := *bonds[0]
+	bond.Coupon.Maturity = time.Date(2027, 9, 30, 0, 0, 0, 0, time.UTC)
+	_, err = new(Accrual).Assign(&bond, settle)
+	assert.Error(err)
+}
+
+type dayNumberCircuit struct {
+	Date [3]frontend.Variable `gnark:",public"`
+	Days frontend.Variable    `gnark:",public"`
+	Hint DayNumberHint        `gnark:",private"`
+}
+
+func (circuit *dayNumberCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	cs.AssertIsEqual(circuit.Days, dayNumber(cs, curveID, circuit.Date[0], circuit.Date[1], circuit.Date[2], &circuit.Hint))
+	return nil
+}
+
+func TestDayNumberCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit dayNumberCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	// the day number of a date that doesn't exist is the one of the day it overflows to
+	witness := func(year int, month time.Month, day int) *dayNumberCircuit {
+		var w dayNumberCircuit
+		w.Date[0].Assign(year)
+		w.Date[1].Assign(int(month))
+		w.Date[2].Assign(day)
+		w.Days.Assign(big.NewInt(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400))
+		w.Hint.Assign(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC))
+		return &w
+	}
+
+	assert.SolvingSucceeded(r1cs, witness(2021, time.November, 23))
+	assert.SolvingSucceeded(r1cs, witness(2021, time.December, 31))
+	assert.SolvingSucceeded(r1cs, witness(2021, time.February, 28))
+
+	// the day is within the month, February has 29 days in leap years only
+	assert.SolvingFailed(r1cs, witness(2021, time.April, 31))
+	assert.SolvingFailed(r1cs, witness(2021, time.February, 29))
+	assert.SolvingSucceeded(r1cs, witness(2024, time.February, 29))
+	assert.SolvingFailed(r1cs, witness(2024, time.February, 30))
+	assert.SolvingFailed(r1cs, witness(2100, time.February, 29))
+	assert.SolvingSucceeded(r1cs, witness(2000, time.February, 29))
+}
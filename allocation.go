@@ -0,0 +1,179 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+var (
+	errMalformedSizedQuote = errors.New("price and size must be positive integers smaller than 2^64")
+	errUnfilled            = errors.New("quotes don't cover the bond size")
+	errAllocationDealers   = errors.New("allocation needs the key of every quoting dealer")
+)
+
+// SizedQuote is a dealer quote for part of a large RFQ: a price and the largest size the dealer takes at it.
+// Price is encoded as the quotes of bondCircuit, clean price * Bond.Size in cents, so the same price signs
+// the same number in both circuits: 92.63 for the 1,550,000 of CA29250NAS41 is 143576500.
+type SizedQuote struct {
+	Price     *big.Int // clean price * Bond.Size in cents, whatever the size of the quote
+	Size      *big.Int // face amount, in the unit of Bond.Size
+	Signature []byte   // Sign(MiMC(bond hash, RFQ ID, price, size))
+}
+
+// sizedQuoteHash is the message of a sized quote signed for a bond in a RFQ, checked in allocationCircuit
+func sizedQuoteHash(bondHash []byte, rfqID, price, size *big.Int) []byte {
+	return hashFields(new(big.Int).SetBytes(bondHash), rfqID, price, size)
+}
+
+// SignSizedQuote signs a price for at most size of the bond with that hash, in the RFQ with that ID
+func SignSizedQuote(key signature.Signer, bondHash []byte, rfqID, price, size *big.Int) (*SizedQuote, error) {
+	for _, v := range []*big.Int{price, size} {
+		if v.Sign() <= 0 || v.Cmp(maxQuote) >= 0 {
+			return nil, errMalformedSizedQuote
+		}
+	}
+	sig, err := key.Sign(sizedQuoteHash(bondHash, rfqID, price, size), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	return &SizedQuote{Price: new(big.Int).Set(price), Size: new(big.Int).Set(size), Signature: sig}, nil
+}
+
+// Verify checks the quote is signed by the dealer for the bond in the RFQ with that ID
+func (q *SizedQuote) Verify(dealer signature.PublicKey, bondHash []byte, rfqID *big.Int) bool {
+	return verifySignature(dealer, q.Signature, sizedQuoteHash(bondHash, rfqID, q.Price, q.Size))
+}
+
+// Fill is the part of the bond allocated to the dealer quoting in slot Dealer
+type Fill struct {
+	Dealer int
+	Price  *big.Int
+	Size   *big.Int
+}
+
+// Allocate fills size from the quotes in price order, best first, each one up to its size, so only the
+// last dealer filled can get less than it quoted. Equal prices are filled in slot order. It returns a
+// fill for every quote, with a zero size for the quotes not needed.
+func Allocate(size *big.Int, quotes []*SizedQuote) ([]Fill, error) {
+	order := make([]int, len(quotes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return quotes[order[a]].Price.Cmp(quotes[order[b]].Price) < 0
+	})
+
+	remaining := new(big.Int).Set(size)
+	fills := make([]Fill, len(quotes))
+	for k, i := range order {
+		fill := new(big.Int).Set(quotes[i].Size)
+		if fill.Cmp(remaining) > 0 {
+			fill.Set(remaining)
+		}
+		remaining.Sub(remaining, fill)
+		fills[k] = Fill{Dealer: i, Price: quotes[i].Price, Size: fill}
+	}
+	if remaining.Sign() > 0 {
+		return nil, errUnfilled
+	}
+	return fills, nil
+}
+
+// AllocationCommitment is MiMC(bond hash, then the dealer key, price and size of each fill in order),
+// public output of allocationCircuit. dealers are the keys of the quotes, by slot.
+func AllocationCommitment(bondHash []byte, dealers []signature.PublicKey, fills []Fill) ([]byte, error) {
+	elements := []*big.Int{new(big.Int).SetBytes(bondHash)}
+	for _, fill := range fills {
+		if fill.Dealer < 0 || fill.Dealer >= len(dealers) {
+			return nil, errAllocationDealers
+		}
+		x, y := parsePoint(ecc.BN254, dealers[fill.Dealer].Bytes())
+		elements = append(elements, new(big.Int).SetBytes(x), new(big.Int).SetBytes(y), fill.Price, fill.Size)
+	}
+	return hashFields(elements...), nil
+}
+
+// allocationCircuit proves a large RFQ was split across the dealers in price order: the best quotes
+// are filled up to their size, the last one filled can be partial, and the fills add up to the size of
+// the bond. The allocation list (dealer, price, size) is public through its commitment only.
+type allocationCircuit struct {
+	Bond             frontend.Variable                 `gnark:",public"`  // hash of the bond attributes
+	RFQID            frontend.Variable                 `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	PublicKeyCpts    [3]PublicKey                      `gnark:",public"`  // counterparties asked for a quote
+	Allocation       frontend.Variable                 `gnark:",public"`  // AllocationCommitment of the fills
+	BondAttributes   [bondFieldsSize]frontend.Variable `gnark:",private"` // as in Bond.Fields, Size is the size to fill
+	Prices           [3]frontend.Variable              `gnark:",private"` // clean price * Bond.Size in cents
+	Sizes            [3]frontend.Variable              `gnark:",private"` // largest size of each quote
+	SizedQuoteSigned [3]Signature                      `gnark:",private"` // Sign(Bond hash, RFQID, price, size)
+	Order            [3]frontend.Variable              `gnark:",private"` // slots by price, best first
+	Fills            [3]frontend.Variable              `gnark:",private"` // size allocated to each slot of Order
+}
+
+func (circuit *allocationCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	// the size to fill is the one of the bond
+	cs.AssertIsEqual(circuit.Bond, mimc.Hash(cs, circuit.BondAttributes[:]...))
+	mustBeDistinctKeys(cs, curveID, circuit.PublicKeyCpts)
+
+	var xs, ys [3]frontend.Variable
+	for i := range circuit.Prices {
+		cs.AssertIsEqual(cs.IsZero(circuit.Prices[i], curveID), 0)
+		cs.AssertIsEqual(cs.IsZero(circuit.Sizes[i], curveID), 0)
+
+		circuit.PublicKeyCpts[i].Curve = params
+		message := mimc.Hash(cs, circuit.Bond, circuit.RFQID, circuit.Prices[i], circuit.Sizes[i])
+		mustBeCanonical(cs, circuit.SizedQuoteSigned[i])
+		eddsa.Verify(cs, circuit.SizedQuoteSigned[i], message, circuit.PublicKeyCpts[i])
+
+		xs[i], ys[i] = circuit.PublicKeyCpts[i].A.X, circuit.PublicKeyCpts[i].A.Y
+	}
+
+	// Order is a permutation of the slots
+	for i := range circuit.Order {
+		for j := i + 1; j < len(circuit.Order); j++ {
+			cs.AssertIsEqual(cs.IsZero(cs.Sub(circuit.Order[i], circuit.Order[j]), curveID), 0)
+		}
+	}
+
+	allocation := []frontend.Variable{circuit.Bond}
+	filled := cs.Constant(0)
+	var previousPrice, previousSize, previousFill frontend.Variable
+	for k := range circuit.Order {
+		price := selectByIndex(cs, curveID, circuit.Order[k], circuit.Prices[:])
+		size := selectByIndex(cs, curveID, circuit.Order[k], circuit.Sizes[:])
+		fill := circuit.Fills[k]
+
+		// a quote is filled up to its size, and only once the better ones are filled entirely
+		cs.AssertIsLessOrEqual(fill, size)
+		if k > 0 {
+			cs.AssertIsLessOrEqual(previousPrice, price)
+			cs.AssertIsEqual(cs.Mul(fill, cs.Sub(previousSize, previousFill)), 0)
+		}
+		previousPrice, previousSize, previousFill = price, size, fill
+		filled = cs.Add(filled, fill)
+
+		allocation = append(allocation,
+			selectByIndex(cs, curveID, circuit.Order[k], xs[:]),
+			selectByIndex(cs, curveID, circuit.Order[k], ys[:]),
+			price, fill)
+	}
+	cs.AssertIsEqual(filled, circuit.BondAttributes[1])
+	cs.AssertIsEqual(circuit.Allocation, mimc.Hash(cs, allocation...))
+
+	return nil
+}
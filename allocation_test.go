@@ -0,0 +1,109 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestAllocation(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	// 1,550,000 of the bond, more than any dealer takes alone
+	f := newRFQFixture("CA29250NAS41")
+	assert.Equal("1550000", f.bond.Size)
+	bondHash, dealers := f.bondHash, f.dealers()
+	rfqID := big.NewInt(1)
+	bondFields, err := f.bond.Fields()
+	assert.NoError(err)
+
+	// prices are encoded as the bondCircuit quotes for the bond
+	prices := []*big.Int{quoteAmount(f.bond, "92.63"), quoteAmount(f.bond, "92.50"), quoteAmount(f.bond, "93")}
+	assert.Equal(big.NewInt(143576500), prices[0])
+	var quotes []*SizedQuote
+	for i, size := range []int64{1000000, 500000, 2000000} {
+		quote, err := SignSizedQuote(f.keys[i], bondHash, rfqID, prices[i], big.NewInt(size))
+		assert.NoError(err)
+		assert.True(quote.Verify(dealers[i], bondHash, rfqID))
+		assert.False(quote.Verify(dealers[(i+1)%3], bondHash, rfqID))
+		assert.False(quote.Verify(dealers[i], bondHash, big.NewInt(2)))
+		quotes = append(quotes, quote)
+	}
+	_, err = SignSizedQuote(f.keys[0], bondHash, rfqID, prices[0], big.NewInt(0))
+	assert.True(errors.Is(err, errMalformedSizedQuote), err)
+
+	// the best price is filled entirely, then the next one, and the last dealer filled gets the rest
+	fills, err := Allocate(big.NewInt(1550000), quotes)
+	assert.NoError(err)
+	assert.Equal([]Fill{
+		{Dealer: 1, Price: prices[1], Size: big.NewInt(500000)},
+		{Dealer: 0, Price: prices[0], Size: big.NewInt(1000000)},
+		{Dealer: 2, Price: prices[2], Size: big.NewInt(50000)},
+	}, fills)
+	_, err = Allocate(big.NewInt(3500001), quotes)
+	assert.True(errors.Is(err, errUnfilled), err)
+	allocation, err := AllocationCommitment(bondHash, dealers, fills)
+	assert.NoError(err)
+
+	var circuit allocationCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(fills []Fill) *allocationCircuit {
+		var w allocationCircuit
+		w.Bond.Assign(bondHash)
+		w.RFQID.Assign(rfqID)
+		for i := range bondFields {
+			w.BondAttributes[i].Assign(bondFields[i])
+		}
+		f.assignKeys(&w.PublicKeyCpts)
+		for i, quote := range quotes {
+			w.Prices[i].Assign(quote.Price)
+			w.Sizes[i].Assign(quote.Size)
+			assert.NoError(assignSignature(&w.SizedQuoteSigned[i], quote.Signature))
+		}
+		for k, fill := range fills {
+			w.Order[k].Assign(fill.Dealer)
+			w.Fills[k].Assign(fill.Size)
+		}
+		commitment, err := AllocationCommitment(bondHash, dealers, fills)
+		assert.NoError(err)
+		w.Allocation.Assign(commitment)
+		return &w
+	}
+	fill := func(dealer int, size int64) Fill {
+		return Fill{Dealer: dealer, Price: quotes[dealer].Price, Size: big.NewInt(size)}
+	}
+
+	assert.SolvingSucceeded(r1cs, witness(fills))
+
+	// the commitment is the one of the allocation list
+	bad := witness(fills)
+	bad.Allocation = frontend.Variable{}
+	bad.Allocation.Assign(new(big.Int).Add(new(big.Int).SetBytes(allocation), big.NewInt(1)))
+	assert.SolvingFailed(r1cs, bad)
+
+	// the quotes are the ones signed for this RFQ
+	bad = witness(fills)
+	bad.RFQID = frontend.Variable{}
+	bad.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, bad)
+
+	// quotes are taken in price order
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(0, 1000000), fill(1, 500000), fill(2, 50000)}))
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(1, 500000), fill(2, 1050000), fill(0, 0)}))
+
+	// a quote is only filled once the better ones are filled entirely, up to its size
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(1, 400000), fill(0, 1000000), fill(2, 150000)}))
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(1, 550000), fill(0, 1000000), fill(2, 0)}))
+
+	// and the fills add up to the size of the bond
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(1, 500000), fill(0, 1000000), fill(2, 40000)}))
+	assert.SolvingFailed(r1cs, witness([]Fill{fill(1, 500000), fill(1, 1000000), fill(2, 50000)}))
+}
@@ -0,0 +1,105 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// basisPoints is 100%, the tolerance of the best execution band is in basis points of the reference price
+const basisPoints = 10000
+
+var (
+	errMalformedReference = errors.New("reference price must be a positive integer smaller than 2^64")
+	errTolerance          = errors.New("tolerance must be between 0 and 10000 basis points")
+)
+
+// ReferencePrice is an independent composite price of a bond at a time, signed by a market data publisher.
+// Price is encoded as the quotes: clean price * size in cents, so it compares to the accepted quote.
+type ReferencePrice struct {
+	Price     *big.Int
+	Time      time.Time // execution time the price is published for, to the second
+	Signature []byte    // Sign(MiMC(bond hash, price, unix time))
+}
+
+// referencePriceHash is the message a market data publisher signs for a reference price of a bond
+func referencePriceHash(bondHash []byte, price *big.Int, at time.Time) []byte {
+	return hashFields(new(big.Int).SetBytes(bondHash), price, big.NewInt(at.Unix()))
+}
+
+// SignReferencePrice signs the reference price of the bond with that hash at a time, with the key of a market data publisher
+func SignReferencePrice(publisher signature.Signer, bondHash []byte, price *big.Int, at time.Time) (*ReferencePrice, error) {
+	if price.Sign() <= 0 || price.Cmp(maxQuote) >= 0 || at.Unix() < 0 {
+		return nil, errMalformedReference
+	}
+	sig, err := publisher.Sign(referencePriceHash(bondHash, price, at), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	return &ReferencePrice{Price: new(big.Int).Set(price), Time: at, Signature: sig}, nil
+}
+
+// Verify checks the reference price is signed by the publisher for the bond
+func (r *ReferencePrice) Verify(publisher signature.PublicKey, bondHash []byte) bool {
+	return verifySignature(publisher, r.Signature, referencePriceHash(bondHash, r.Price, r.Time))
+}
+
+// WithinBand reports whether the quote is within toleranceBp basis points of the reference price:
+// |quote - reference| <= toleranceBp / 10000 * reference
+func (r *ReferencePrice) WithinBand(quote *big.Int, toleranceBp int) (bool, error) {
+	if toleranceBp < 0 || toleranceBp > basisPoints {
+		return false, errTolerance
+	}
+	diff := new(big.Int).Sub(quote, r.Price)
+	diff.Abs(diff).Mul(diff, big.NewInt(basisPoints))
+	band := new(big.Int).Mul(r.Price, big.NewInt(int64(toleranceBp)))
+	return diff.Cmp(band) <= 0, nil
+}
+
+// BestExecution holds the private values proving the accepted quote is within a tolerance of a reference price
+type BestExecution struct {
+	Reference       frontend.Variable // reference price, encoded as the quotes
+	ReferenceSigned Signature         // Sign(MiMC(bond hash, reference, time)) by the market data publisher
+	Above           frontend.Variable // 1 if the quote is above the reference price
+}
+
+// mustBeWithinBand proves |quote - reference| <= toleranceBp / 10000 * reference, where the reference price
+// of the bond at the public time is signed by publisher and toleranceBp is at most 10000.
+func (b *BestExecution) mustBeWithinBand(cs *frontend.ConstraintSystem, curveID ecc.ID, hash mimc.MiMC,
+	bond frontend.Variable, publisher PublicKey, at, toleranceBp, quote frontend.Variable) {
+
+	// the reference price is the one of the publisher for the bond at that time
+	message := hash.Hash(cs, bond, b.Reference, at)
+	mustBeCanonical(cs, b.ReferenceSigned)
+	eddsa.Verify(cs, b.ReferenceSigned, message, publisher)
+
+	// all terms stay far below the field size: reference < 2^64, diff <= reference, tolerance <= 10000
+	cs.AssertIsEqual(cs.IsZero(b.Reference, curveID), 0)
+	cs.AssertIsLessOrEqual(b.Reference, new(big.Int).Sub(maxQuote, big.NewInt(1)))
+	cs.AssertIsLessOrEqual(toleranceBp, basisPoints)
+
+	diff := cs.Select(b.Above, cs.Sub(quote, b.Reference), cs.Sub(b.Reference, quote))
+	cs.AssertIsLessOrEqual(diff, b.Reference)
+	cs.AssertIsLessOrEqual(cs.Mul(diff, basisPoints), cs.Mul(toleranceBp, b.Reference))
+}
+
+// Assign sets the witness values proving quote is within the band around the reference price
+func (b *BestExecution) Assign(reference *ReferencePrice, quote *big.Int) error {
+	if reference.Price.Sign() <= 0 || reference.Price.Cmp(maxQuote) >= 0 {
+		return errMalformedReference
+	}
+	b.Reference.Assign(reference.Price)
+	if quote.Cmp(reference.Price) > 0 {
+		b.Above.Assign(1)
+	} else {
+		b.Above.Assign(0)
+	}
+	return assignSignature(&b.ReferenceSigned, reference.Signature)
+}
@@ -0,0 +1,114 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+type bestExecutionCircuit struct {
+	Bond          frontend.Variable `gnark:",public"`
+	PublisherKey  PublicKey         `gnark:",public"`
+	Time          frontend.Variable `gnark:",public"`
+	ToleranceBp   frontend.Variable `gnark:",public"`
+	AcceptedQuote frontend.Variable `gnark:",public"`
+	BestExecution BestExecution     `gnark:",private"`
+}
+
+func (circuit *bestExecutionCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+	circuit.PublisherKey.Curve = params
+	circuit.BestExecution.mustBeWithinBand(cs, curveID, mimc, circuit.Bond, circuit.PublisherKey,
+		circuit.Time, circuit.ToleranceBp, circuit.AcceptedQuote)
+	return nil
+}
+
+func TestBestExecution(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("CA29250NAT24")
+	bond, bondHash := f.bond, f.bondHash
+
+	publisher, err := keystore.Generate()
+	assert.NoError(err)
+	execution := time.Date(2021, 11, 19, 15, 30, 0, 0, time.UTC)
+
+	// composite price of 92.80 for the 550000 bond, 51040000 cents
+	reference, err := SignReferencePrice(publisher, bondHash, quoteAmount(bond, "92.80"), execution)
+	assert.NoError(err)
+	assert.True(reference.Verify(publisher.Public(), bondHash))
+	_, err = SignReferencePrice(publisher, bondHash, big.NewInt(0), execution)
+	assert.True(errors.Is(err, errMalformedReference), err)
+
+	// 92.63 is 0.17 below the reference, about 18.3bp
+	accepted := quoteAmount(bond, "92.63")
+	for tolerance, within := range map[int]bool{18: false, 19: true, 250: true} {
+		ok, err := reference.WithinBand(accepted, tolerance)
+		assert.NoError(err)
+		assert.Equal(within, ok, tolerance)
+	}
+	_, err = reference.WithinBand(accepted, basisPoints+1)
+	assert.True(errors.Is(err, errTolerance), err)
+
+	var circuit bestExecutionCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(reference *ReferencePrice, quote *big.Int, tolerance int) *bestExecutionCircuit {
+		var w bestExecutionCircuit
+		w.Bond.Assign(bondHash)
+		assignPublicKey(&w.PublisherKey, publisher.Public().Bytes())
+		w.Time.Assign(big.NewInt(execution.Unix()))
+		w.ToleranceBp.Assign(tolerance)
+		w.AcceptedQuote.Assign(quote)
+		assert.NoError(w.BestExecution.Assign(reference, quote))
+		return &w
+	}
+
+	// below the reference
+	assert.SolvingSucceeded(r1cs, witness(reference, accepted, 19))
+	assert.SolvingFailed(r1cs, witness(reference, accepted, 18))
+
+	// above the reference, 93 is 0.20 or about 21.6bp
+	above := quoteAmount(bond, "93")
+	assert.SolvingSucceeded(r1cs, witness(reference, above, 22))
+	assert.SolvingFailed(r1cs, witness(reference, above, 21))
+
+	// at the reference, even with no tolerance
+	assert.SolvingSucceeded(r1cs, witness(reference, reference.Price, 0))
+
+	// the side of the reference can't be flipped to wrap the difference around the field
+	bad := witness(reference, accepted, 19)
+	bad.BestExecution.Above = frontend.Variable{}
+	bad.BestExecution.Above.Assign(1)
+	assert.SolvingFailed(r1cs, bad)
+
+	// the tolerance is at most 100%
+	assert.SolvingFailed(r1cs, witness(reference, accepted, basisPoints+1))
+
+	// the reference price is the one signed for the bond at the execution time
+	bad = witness(reference, accepted, 19)
+	bad.Time = frontend.Variable{}
+	bad.Time.Assign(big.NewInt(execution.Add(time.Minute).Unix()))
+	assert.SolvingFailed(r1cs, bad)
+
+	forged := *reference
+	forged.Price = quoteAmount(bond, "92.55")
+	assert.False(forged.Verify(publisher.Public(), bondHash))
+	assert.SolvingFailed(r1cs, witness(&forged, accepted, 19))
+}
@@ -16,19 +16,21 @@ type PublicKey = eddsa.PublicKey
 // Signature
 type Signature = eddsa.Signature
 
-func parseSignature(id ecc.ID, buf []byte) ([]byte, []byte, []byte, []byte) {
-
-	var pointbn254 edwardsbn254.PointAffine
+// parseSignature splits a signature into the coordinates of R and the two halves of S,
+// which must be smaller than the subgroup order
+func parseSignature(id ecc.ID, buf []byte) ([]byte, []byte, []byte, []byte, error) {
 
 	switch id {
 	case ecc.BN254:
-		pointbn254.SetBytes(buf[:32])
+		if err := checkCanonical(buf); err != nil {
+			return nil, nil, nil, nil, err
+		}
 		a, b := parsePoint(id, buf)
 		s1 := buf[32:48] // r is 256 bits, so s = 2^128*s1 + s2
 		s2 := buf[48:]
-		return a[:], b[:], s1, s2
+		return a[:], b[:], s1, s2, nil
 	default:
-		return buf, buf, buf, buf
+		return buf, buf, buf, buf, nil
 	}
 }
 
@@ -46,20 +48,63 @@ func parsePoint(id ecc.ID, buf []byte) ([]byte, []byte) {
 	}
 }
 
+// assignSignature sets the witness values of sig from its binary representation
+func assignSignature(sig *Signature, buf []byte) error {
+	rx, ry, s1, s2, err := parseSignature(ecc.BN254, buf)
+	if err != nil {
+		return err
+	}
+	sig.R.X.Assign(rx)
+	sig.R.Y.Assign(ry)
+	sig.S1.Assign(s1)
+	sig.S2.Assign(s2)
+	return nil
+}
+
+// assignPublicKey sets the witness values of pk from its binary representation
+func assignPublicKey(pk *PublicKey, buf []byte) {
+	x, y := parsePoint(ecc.BN254, buf)
+	pk.A.X.Assign(x)
+	pk.A.Y.Assign(y)
+}
+
 // this structure declares the public inputs and secrets keys
 type bondCircuit struct {
 	//Accepted Bid 92.63 by the 2 parties prior to creating the circuit
 	//Before the circuit is build the initiator knows  the responder whos bid was accepted
-	AcceptedQuoteQuery  frontend.Variable    `gnark:",public"`  // 92.63
-	AcceptedQuoteSigned Signature            `gnark:",public"`  // to prevent spam
-	PublicKeyCpts       [3]PublicKey         `gnark:",public"`  // Public key to check quotes signed - The reason for the public keys is to confirm who participated in providing quotes
-	Bond                frontend.Variable    `gnark:",public"`  // hash of Isin, Ticker and Size
-	SignatureCpts       [3]Signature         `gnark:",private"` // Sign(quote)
-	QuoteFromCpts       [3]frontend.Variable `gnark:",private"` // Example: 92.63
-	AcceptedQuotePubKey PublicKey            `gnark:",private"` // It is going to be PublicKeyCpt1 or PublicKeyCpt2 or PublicKeyC
-	AcceptedQuote       frontend.Variable    `gnark:",private"` //
-	RejectedQuotes      [2]frontend.Variable `gnark:",private"` //
-	BondQuoteSignedCpts [3]Signature         `gnark:",private"` // Sign(Bond hash, quote)
+	AcceptedQuoteQuery  frontend.Variable                 `gnark:",public"`  // 92.63
+	AcceptedQuoteSigned Signature                         `gnark:",public"`  // to prevent spam
+	PublicKeyCpts       [3]PublicKey                      `gnark:",public"`  // Public key to check quotes signed - The reason for the public keys is to confirm who participated in providing quotes
+	Bond                frontend.Variable                 `gnark:",public"`  // hash of Isin, Ticker and Size
+	SignatureCpts       [3]Signature                      `gnark:",private"` // Sign(quote)
+	QuoteFromCpts       [3]frontend.Variable              `gnark:",private"` // Example: 92.63
+	AcceptedQuotePubKey PublicKey                         `gnark:",private"` // It is going to be PublicKeyCpt1 or PublicKeyCpt2 or PublicKeyC
+	WinnerIndex         frontend.Variable                 `gnark:",private"` // slot of the winning dealer in PublicKeyCpts
+	AcceptedQuote       frontend.Variable                 `gnark:",private"` //
+	RejectedQuotes      [2]frontend.Variable              `gnark:",private"` //
+	BondQuoteSignedCpts [3]Signature                      `gnark:",private"` // Sign(Bond hash, RFQID, quote)
+	BondAttributes      [bondFieldsSize]frontend.Variable `gnark:",private"` // Isin, Size and Ticker hashed into Bond
+	RegulatorKey        PublicKey                         `gnark:",public"`  // Public key of the regulator
+	RegulatorReport     Ciphertext                        `gnark:",public"`  // Accepted quote, winner and bond encrypted to RegulatorKey
+	EncryptionNonce     frontend.Variable                 `gnark:",private"` // ElGamal randomness
+	SettlementDate      [3]frontend.Variable              `gnark:",public"`  // year, month, day
+	SettlementAmount    frontend.Variable                 `gnark:",public"`  // AcceptedQuote plus accrued interest, in cents
+	Accrual             Accrual                           `gnark:",private"` // coupon period around the settlement date
+	CouponSteps         StepCoupon                        `gnark:",private"` // step-up schedule of the coupon
+	InstrumentType      frontend.Variable                 `gnark:",public"`  // type of the bond, selects the rules applied
+	QuoteCommitments    [3]frontend.Variable              `gnark:",public"`  // published by the dealers before the deadline
+	QuoteBlindings      [3]frontend.Variable              `gnark:",private"` // revealed with the quotes after the deadline
+	InitiatorKey        PublicKey                         `gnark:",public"`  // Public key signing the receipts of the quotes
+	ReceiptCount        frontend.Variable                 `gnark:",public"`  // number of quotes the initiator acknowledged
+	Receipts            [3]Signature                      `gnark:",private"` // Sign(Bond hash, index, commitment) by the initiator
+	QuoteCount          frontend.Variable                 `gnark:",public"`  // number of dealers who quoted rather than passed
+	MinQuotes           frontend.Variable                 `gnark:",public"`  // best execution: fewest competing quotes accepted
+	RFQID               frontend.Variable                 `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	Nullifiers          [3]frontend.Variable              `gnark:",public"`  // MiMC(RFQID, BondQuoteSignedCpts[i]), used once
+	ReferenceKey        PublicKey                         `gnark:",public"`  // Public key of the market data publisher
+	ReferenceTime       frontend.Variable                 `gnark:",public"`  // execution time of the reference price, unix seconds
+	ToleranceBp         frontend.Variable                 `gnark:",public"`  // best execution band around the reference, in basis points
+	BestExecution       BestExecution                     `gnark:",private"` // reference price signed by the publisher
 }
 
 // this function is called on set up/compile
@@ -80,6 +125,14 @@ func (circuit *bondCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem
 	cs.AssertIsLessOrEqual(circuit.AcceptedQuote, circuit.RejectedQuotes[1])
 	cs.AssertIsEqual(circuit.AcceptedQuote, circuit.AcceptedQuoteQuery)
 
+	// dealers declining sign a pass, which is never the smallest quote, and aren't counted as quotes
+	quoteCount := mustBeBestQuote(cs, curveID, circuit.AcceptedQuote, circuit.QuoteFromCpts)
+	cs.AssertIsEqual(circuit.QuoteCount, quoteCount)
+
+	// at least MinQuotes competing quotes, from as many different dealers
+	mustBeDistinctKeys(cs, curveID, circuit.PublicKeyCpts)
+	mustCompete(cs, quoteCount, circuit.MinQuotes)
+
 	// If winner quote is from Cpt1, Cpt2 or Cpt3, one of the subtraction is going to return zero
 	// The circuit is build with all quotes received from responders
 	subCpt1 := cs.Sub(circuit.QuoteFromCpts[0], circuit.AcceptedQuote)
@@ -103,6 +156,28 @@ func (circuit *bondCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem
 		return err
 	}
 
+	// dealer keys are points of the curve outside its small subgroup
+	for i := range circuit.PublicKeyCpts {
+		mustBeValidKey(cs, params, circuit.PublicKeyCpts[i])
+	}
+
+	// signatures are canonical, S smaller than the subgroup order, so none can be malleated into another one
+	mustBeCanonical(cs, circuit.AcceptedQuoteSigned)
+	for i := range circuit.SignatureCpts {
+		mustBeCanonical(cs, circuit.SignatureCpts[i])
+	}
+
+	// the winner is one of the dealers asked: its key and quote are the ones of its slot
+	var xs, ys [3]frontend.Variable
+	for i := range circuit.PublicKeyCpts {
+		xs[i], ys[i] = circuit.PublicKeyCpts[i].A.X, circuit.PublicKeyCpts[i].A.Y
+	}
+	winnerX := selectByIndex(cs, curveID, circuit.WinnerIndex, xs[:])
+	winnerY := selectByIndex(cs, curveID, circuit.WinnerIndex, ys[:])
+	cs.AssertIsEqual(circuit.AcceptedQuotePubKey.A.X, winnerX)
+	cs.AssertIsEqual(circuit.AcceptedQuotePubKey.A.Y, winnerY)
+	cs.AssertIsEqual(circuit.AcceptedQuote, selectByIndex(cs, curveID, circuit.WinnerIndex, circuit.QuoteFromCpts[:]))
+
 	circuit.AcceptedQuotePubKey.Curve = params
 	eddsa.Verify(cs, circuit.AcceptedQuoteSigned, circuit.AcceptedQuote, circuit.AcceptedQuotePubKey)
 
@@ -117,21 +192,53 @@ func (circuit *bondCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem
 	circuit.PublicKeyCpts[2].Curve = params
 	eddsa.Verify(cs, circuit.SignatureCpts[2], circuit.QuoteFromCpts[2], circuit.PublicKeyCpts[2])
 
-	//check Isin + quote
+	// the quote is valid only for that bond, that RFQ and the cpt, as in coverCircuit and lostQuoteCircuit
 	mimc, _ := mimc.NewMiMC("seed", curveID)
-	IsinQuoteFromCpt1Hash := mimc.Hash(cs, circuit.Bond, circuit.QuoteFromCpts[0])
-	IsinQuoteFromCpt2Hash := mimc.Hash(cs, circuit.Bond, circuit.QuoteFromCpts[1])
-	IsinQuoteFromCpt3Hash := mimc.Hash(cs, circuit.Bond, circuit.QuoteFromCpts[2])
+	for i := range circuit.BondQuoteSignedCpts {
+		mustBeSignedQuote(cs, mimc, params, circuit.Bond, circuit.RFQID, circuit.QuoteFromCpts[i],
+			circuit.BondQuoteSignedCpts[i], circuit.PublicKeyCpts[i])
+	}
 
-	// the quote is valid only for that bond and the cpt1
-	circuit.PublicKeyCpts[0].Curve = params
-	eddsa.Verify(cs, circuit.BondQuoteSignedCpts[0], IsinQuoteFromCpt1Hash, circuit.PublicKeyCpts[0])
+	// quotes were fixed before the deadline: each one opens the commitment of its dealer
+	for i := range circuit.QuoteCommitments {
+		commitment := quoteCommitment(cs, mimc, circuit.RFQID, circuit.Bond, circuit.QuoteFromCpts[i], circuit.BondQuoteSignedCpts[i], circuit.QuoteBlindings[i])
+		cs.AssertIsEqual(circuit.QuoteCommitments[i], commitment)
+	}
 
-	circuit.PublicKeyCpts[1].Curve = params
-	eddsa.Verify(cs, circuit.BondQuoteSignedCpts[1], IsinQuoteFromCpt2Hash, circuit.PublicKeyCpts[1])
+	// each quote can be used in one accepted proof only: its nullifier is recorded when the proof is
+	for i := range circuit.Nullifiers {
+		cs.AssertIsEqual(circuit.Nullifiers[i], quoteNullifier(cs, mimc, circuit.RFQID, circuit.BondQuoteSignedCpts[i]))
+	}
 
-	circuit.PublicKeyCpts[2].Curve = params
-	eddsa.Verify(cs, circuit.BondQuoteSignedCpts[2], IsinQuoteFromCpt3Hash, circuit.PublicKeyCpts[2])
+	// no acknowledged quote was left out: receipt i is for the i-th commitment and all receipts are used
+	mustBeReceived(cs, mimc, params, circuit.Bond, circuit.InitiatorKey, circuit.ReceiptCount, circuit.QuoteCommitments, circuit.Receipts)
+
+	// Bond is the hash of the attributes sent to the regulator
+	bondHash := mimc.Hash(cs, circuit.BondAttributes[:]...)
+	cs.AssertIsEqual(circuit.Bond, bondHash)
+	mustBeInstrumentType(cs, circuit.BondAttributes, circuit.InstrumentType)
+
+	// the regulator can read the trade without the initiator forwarding it
+	report := [tradeReportSize]frontend.Variable{
+		circuit.AcceptedQuote,
+		winnerX,
+		winnerY,
+	}
+	copy(report[3:], circuit.BondAttributes[:])
+
+	circuit.RegulatorKey.Curve = params
+	circuit.RegulatorReport.mustEncrypt(cs, mimc, params, circuit.RegulatorKey.A, circuit.EncryptionNonce, report)
+
+	// settlement amount = clean price * size + accrued interest
+	rate := circuit.CouponSteps.mustApply(cs, curveID, mimc, stepScheduleTerm(cs, curveID, circuit.BondAttributes),
+		circuit.BondAttributes[4], circuit.SettlementDate)
+	accrued := circuit.Accrual.mustAccrue(cs, curveID, circuit.BondAttributes, rate, circuit.SettlementDate)
+	cs.AssertIsEqual(circuit.SettlementAmount, cs.Add(circuit.AcceptedQuote, accrued))
+
+	// best execution: the accepted quote is within ToleranceBp of the reference price at execution time
+	circuit.ReferenceKey.Curve = params
+	circuit.BestExecution.mustBeWithinBand(cs, curveID, mimc, circuit.Bond, circuit.ReferenceKey,
+		circuit.ReferenceTime, circuit.ToleranceBp, circuit.AcceptedQuote)
 
 	return nil
 }
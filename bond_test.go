@@ -2,16 +2,19 @@ package financial
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"math/big"
-	"math/rand"
 	"os"
 	"testing"
+	"time"
+
+	"bloconuts/v0/keystore"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
-	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark-crypto/signature"
 
 	"github.com/consensys/gnark/backend"
@@ -24,6 +27,7 @@ const (
 	pkPath       = "circuit/bond.pk"
 	vkPath       = "circuit/bond.vk"
 	solidityPath = "circuit/bond.sol"
+	registryPath = "circuit/registry.sol"
 )
 
 func TestBondv(t *testing.T) {
@@ -59,10 +63,22 @@ func TestBondv(t *testing.T) {
 	f, err = os.Create(solidityPath)
 	err = vk.ExportSolidity(f)
 
+	// and the registry recording the nullifiers of the proofs it accepts
+	fmt.Println("export solidity registry", registryPath)
+	f, err = os.Create(registryPath)
+	err = ExportNullifierRegistry(f, "bond.sol", 37+tradeReportSize, []int{30 + tradeReportSize, 31 + tradeReportSize, 32 + tradeReportSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewNullifierRegistry()
+
 	/*
 	* Populate test cases
 	 */
 	var testCases = createTestCases()
+	settlementDate := time.Date(2021, 11, 23, 0, 0, 0, 0, time.UTC)
+	executionTime := time.Date(2021, 11, 19, 15, 30, 0, 0, time.UTC)
+	const toleranceBp = 250
 
 	size := len(testCases)
 	for i := 0; i < size; i++ {
@@ -76,28 +92,21 @@ func TestBondv(t *testing.T) {
 		/*
 		* Hash and Signatures
 		 */
-		hashFunc := hash.MIMC_BN254
-		goMimc := hashFunc.New("seed")
 		signature.Register(signature.EDDSA_BN254, eddsabn254.GenerateKeyInterfaces)
 
 		// Create a private/pub key to sign
-		hFunc := goMimc //hash.MIMC_BN254.New("seed")
-		src1 := rand.NewSource(1)
-		src2 := rand.NewSource(2)
-		src3 := rand.NewSource(3)
-		rCpt1 := rand.New(src1)
-		rCpt2 := rand.New(src2)
-		rCpt3 := rand.New(src3)
-
-		privKeyCpt1, err := signature.EDDSA_BN254.New(rCpt1)
+		privKeyCpt1, err := keystore.Generate()
 		pubKeyCpt1 := privKeyCpt1.Public()
 
-		privKeyCpt2, err := signature.EDDSA_BN254.New(rCpt2)
+		privKeyCpt2, err := keystore.Generate()
 		pubKeyBCpt2 := privKeyCpt2.Public()
 
-		privKeyCpt3, err := signature.EDDSA_BN254.New(rCpt3)
+		privKeyCpt3, err := keystore.Generate()
 		pubKeyCpt3 := privKeyCpt3.Public()
 
+		privKeyRegulator, err := keystore.Generate()
+		pubKeyRegulator := privKeyRegulator.Public()
+
 		/* Private and Public Key for A,B and C created */
 
 		//Set values for quotes from A,B and C
@@ -105,9 +114,14 @@ func TestBondv(t *testing.T) {
 		QuoteFromCpt2 := testCase.quoteCpt2
 		QuoteFromCpt3 := testCase.quoteCpt3
 
-		signatureCpt1, err := privKeyCpt1.Sign(QuoteFromCpt1[:], hFunc)
-		signatureCpt2, err := privKeyCpt2.Sign(QuoteFromCpt2[:], hFunc)
-		signatureCpt3, err := privKeyCpt3.Sign(QuoteFromCpt3[:], hFunc)
+		// dealers sign through a QuoteSigner, in memory here, for that RFQ only
+		rfqID := big.NewInt(int64(i + 1))
+		signedCpt1, err := NewLocalSigner(privKeyCpt1, nil).SignQuote(testCase.bond, rfqID, new(big.Int).SetBytes(QuoteFromCpt1))
+		signedCpt2, err := NewLocalSigner(privKeyCpt2, nil).SignQuote(testCase.bond, rfqID, new(big.Int).SetBytes(QuoteFromCpt2))
+		signedCpt3, err := NewLocalSigner(privKeyCpt3, nil).SignQuote(testCase.bond, rfqID, new(big.Int).SetBytes(QuoteFromCpt3))
+		signatureCpt1 := signedCpt1.Signature
+		signatureCpt2 := signedCpt2.Signature
+		signatureCpt3 := signedCpt3.Signature
 
 		id := ecc.BN254
 
@@ -118,45 +132,69 @@ func TestBondv(t *testing.T) {
 		witness.AcceptedQuoteQuery.Assign(testCase.acceptedQuote)
 		witness.Bond.Assign(testCase.bondHash)
 
-		goMimc.Reset()
-		goMimc.Write([]byte(IsinHash))
-		goMimc.Write([]byte(testCase.quoteCpt1))
-		var IsinQuoteCpt1Hashed = goMimc.Sum(nil)
-		BondQuoteSignedCpt1, err := privKeyCpt1.Sign(IsinQuoteCpt1Hashed[:], hFunc)
-
-		goMimc.Reset()
-		goMimc.Write([]byte(IsinHash))
-		goMimc.Write([]byte(testCase.quoteCpt2))
-		var IsinQuoteCpt2Hashed = goMimc.Sum(nil)
-		BondQuoteSignedCpt2, err := privKeyCpt2.Sign(IsinQuoteCpt2Hashed[:], hFunc)
-
-		goMimc.Reset()
-		goMimc.Write([]byte(IsinHash))
-		goMimc.Write([]byte(testCase.quoteCpt3))
-		var IsinQuoteCpt3Hashed = goMimc.Sum(nil)
-		BondQuoteSignedCpt3, err := privKeyCpt3.Sign(IsinQuoteCpt3Hashed[:], hFunc)
-
-		sigRxt, sigRyt, sigS1t, sigS2t := parseSignature(id, BondQuoteSignedCpt1)
+		BondQuoteSignedCpt1 := signedCpt1.BondSignature
+		BondQuoteSignedCpt2 := signedCpt2.BondSignature
+		BondQuoteSignedCpt3 := signedCpt3.BondSignature
+
+		// quotes are sealed before the deadline and revealed to the initiator after
+		revealCpt1, commitmentCpt1, err := SealQuote(pubKeyCpt1, rfqID, IsinHash, signedCpt1)
+		revealCpt2, commitmentCpt2, err := SealQuote(pubKeyBCpt2, rfqID, IsinHash, signedCpt2)
+		revealCpt3, commitmentCpt3, err := SealQuote(pubKeyCpt3, rfqID, IsinHash, signedCpt3)
+		commitments := [3][]byte{commitmentCpt1, commitmentCpt2, commitmentCpt3}
+		for j, reveal := range []*QuoteReveal{revealCpt1, revealCpt2, revealCpt3} {
+			witness.QuoteCommitments[j].Assign(commitments[j])
+			witness.QuoteBlindings[j].Assign(reveal.Blinding)
+		}
+
+		// each quote is nullified in this RFQ once the proof is accepted
+		var nullifiers [3][]byte
+		for j, signed := range []*SignedQuote{signedCpt1, signedCpt2, signedCpt3} {
+			nullifiers[j], err = QuoteNullifier(rfqID, signed.BondSignature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			witness.Nullifiers[j].Assign(nullifiers[j])
+		}
+		witness.RFQID.Assign(rfqID)
+
+		// the initiator acknowledges every sealed quote it received
+		privKeyInitiator, err := keystore.Generate()
+		initiatorx, initiatory := parsePoint(id, privKeyInitiator.Public().Bytes())
+		witness.InitiatorKey.A.X.Assign(initiatorx)
+		witness.InitiatorKey.A.Y.Assign(initiatory)
+		witness.ReceiptCount.Assign(len(commitments))
+		witness.QuoteCount.Assign(len(commitments))
+		witness.MinQuotes.Assign(3)
+		for j := range commitments {
+			receipt, err := IssueReceipt(privKeyInitiator, IsinHash, j, commitments[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			assignSignature(&witness.Receipts[j], receipt.Signature)
+		}
+
+		sigRxt, sigRyt, sigS1t, sigS2t, err := parseSignature(id, BondQuoteSignedCpt1)
 		witness.BondQuoteSignedCpts[0].R.X.Assign(sigRxt)
 		witness.BondQuoteSignedCpts[0].R.Y.Assign(sigRyt)
 		witness.BondQuoteSignedCpts[0].S1.Assign(sigS1t)
 		witness.BondQuoteSignedCpts[0].S2.Assign(sigS2t)
 
-		sigRxt, sigRyt, sigS1t, sigS2t = parseSignature(id, BondQuoteSignedCpt2)
+		sigRxt, sigRyt, sigS1t, sigS2t, err = parseSignature(id, BondQuoteSignedCpt2)
 		witness.BondQuoteSignedCpts[1].R.X.Assign(sigRxt)
 		witness.BondQuoteSignedCpts[1].R.Y.Assign(sigRyt)
 		witness.BondQuoteSignedCpts[1].S1.Assign(sigS1t)
 		witness.BondQuoteSignedCpts[1].S2.Assign(sigS2t)
 
-		sigRxt, sigRyt, sigS1t, sigS2t = parseSignature(id, BondQuoteSignedCpt3)
+		sigRxt, sigRyt, sigS1t, sigS2t, err = parseSignature(id, BondQuoteSignedCpt3)
 		witness.BondQuoteSignedCpts[2].R.X.Assign(sigRxt)
 		witness.BondQuoteSignedCpts[2].R.Y.Assign(sigRyt)
 		witness.BondQuoteSignedCpts[2].S1.Assign(sigS1t)
 		witness.BondQuoteSignedCpts[2].S2.Assign(sigS2t)
 
-		AcceptedQuoteSigned, err := privKeyCpt1.Sign(testCase.acceptedQuote[:], hFunc)
+		// Cpt1 quote is always the accepted quote
+		AcceptedQuoteSigned := signedCpt1.Signature
 
-		sigRx, sigRy, sigS1, sigS2 := parseSignature(id, AcceptedQuoteSigned)
+		sigRx, sigRy, sigS1, sigS2, err := parseSignature(id, AcceptedQuoteSigned)
 		witness.AcceptedQuoteSigned.R.X.Assign(sigRx)
 		witness.AcceptedQuoteSigned.R.Y.Assign(sigRy)
 		witness.AcceptedQuoteSigned.S1.Assign(sigS1)
@@ -170,18 +208,72 @@ func TestBondv(t *testing.T) {
 		witness.QuoteFromCpts[1].Assign(QuoteFromCpt2)
 		witness.QuoteFromCpts[2].Assign(QuoteFromCpt3)
 
+		// Bond attributes and the trade report encrypted to the regulator
+		bondFields, err := testCase.bond.Fields()
+		for j := 0; j < len(bondFields); j++ {
+			witness.BondAttributes[j].Assign(bondFields[j])
+		}
+
+		curveOrder := edwardsbn254.GetEdwardsCurve().Order
+		nonce, err := rand.Int(rand.Reader, &curveOrder)
+		report := TradeReport{
+			AcceptedQuote: testCase.acceptedQuote,
+			Dealer:        pubKeyCpt1,
+			Bond:          testCase.bond,
+		}
+		reportCiphertext, err := report.Encrypt(pubKeyRegulator, nonce)
+
+		regulatorx, regulatory := parsePoint(id, pubKeyRegulator.Bytes())
+		witness.RegulatorKey.A.X.Assign(regulatorx)
+		witness.RegulatorKey.A.Y.Assign(regulatory)
+		witness.RegulatorReport.Assign(&reportCiphertext)
+		witness.EncryptionNonce.Assign(nonce)
+
+		// Settlement amount: accepted quote plus the interest accrued at the settlement date
+		accrued, err := witness.Accrual.Assign(testCase.bond, settlementDate)
+		err = witness.CouponSteps.Assign(&testCase.bond.Coupon, settlementDate)
+		settlementAmount := new(big.Int).SetBytes(testCase.acceptedQuote)
+		settlementAmount.Add(settlementAmount, accrued)
+		assignDate(&witness.SettlementDate, settlementDate)
+		witness.SettlementAmount.Assign(settlementAmount)
+		witness.InstrumentType.Assign(int(testCase.bond.Type))
+
+		// best execution: a composite price of 93 at execution time, signed by a market data publisher
+		privKeyPublisher, err := keystore.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		publisherx, publishery := parsePoint(id, privKeyPublisher.Public().Bytes())
+		reference, err := SignReferencePrice(privKeyPublisher, IsinHash, quoteAmount(testCase.bond, "93"), executionTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = witness.BestExecution.Assign(reference, new(big.Int).SetBytes(testCase.acceptedQuote))
+		if err != nil {
+			t.Fatal(err)
+		}
+		witness.ReferenceKey.A.X.Assign(publisherx)
+		witness.ReferenceKey.A.Y.Assign(publishery)
+		witness.ReferenceTime.Assign(big.NewInt(executionTime.Unix()))
+		witness.ToleranceBp.Assign(toleranceBp)
+
+		// dealer keys are checked as the circuit does
+		err = assignDealerKeys(&witness.PublicKeyCpts, [3]signature.PublicKey{pubKeyCpt1, pubKeyBCpt2, pubKeyCpt3})
+		if err != nil {
+			t.Fatal(err)
+		}
+
 		//A
 		pubkeyAx, pubkeyAy := parsePoint(id, pubKeyCpt1.Bytes())
 		var pbAx, pbAy big.Int
 		pbAx.SetBytes(pubkeyAx)
 		pbAy.SetBytes(pubkeyAy)
-		witness.PublicKeyCpts[0].A.X.Assign(pubkeyAx)
-		witness.PublicKeyCpts[0].A.Y.Assign(pubkeyAy)
 
 		witness.AcceptedQuotePubKey.A.X.Assign(pubkeyAx)
 		witness.AcceptedQuotePubKey.A.Y.Assign(pubkeyAy)
+		witness.WinnerIndex.Assign(0)
 
-		sigRx, sigRy, sigS1, sigS2 = parseSignature(id, signatureCpt1)
+		sigRx, sigRy, sigS1, sigS2, err = parseSignature(id, signatureCpt1)
 		witness.SignatureCpts[0].R.X.Assign(sigRx)
 		witness.SignatureCpts[0].R.Y.Assign(sigRy)
 		witness.SignatureCpts[0].S1.Assign(sigS1)
@@ -192,10 +284,8 @@ func TestBondv(t *testing.T) {
 		var pbBAx, pbBAy big.Int
 		pbBAx.SetBytes(pubkeyBAx)
 		pbBAy.SetBytes(pubkeyBAy)
-		witness.PublicKeyCpts[1].A.X.Assign(pubkeyBAx)
-		witness.PublicKeyCpts[1].A.Y.Assign(pubkeyBAy)
 
-		sigBRx, sigBRy, sigBS1, sigBS2 := parseSignature(id, signatureCpt2)
+		sigBRx, sigBRy, sigBS1, sigBS2, err := parseSignature(id, signatureCpt2)
 		witness.SignatureCpts[1].R.X.Assign(sigBRx)
 		witness.SignatureCpts[1].R.Y.Assign(sigBRy)
 		witness.SignatureCpts[1].S1.Assign(sigBS1)
@@ -206,21 +296,60 @@ func TestBondv(t *testing.T) {
 		var pbCAx, pbCAy big.Int
 		pbCAx.SetBytes(pubkeyCAx)
 		pbCAy.SetBytes(pubkeyCAy)
-		witness.PublicKeyCpts[2].A.X.Assign(pubkeyCAx)
-		witness.PublicKeyCpts[2].A.Y.Assign(pubkeyCAy)
 
-		sigCRx, sigCRy, sigCS1, sigCS2 := parseSignature(id, signatureCpt3)
+		sigCRx, sigCRy, sigCS1, sigCS2, err := parseSignature(id, signatureCpt3)
 		witness.SignatureCpts[2].R.X.Assign(sigCRx)
 		witness.SignatureCpts[2].R.Y.Assign(sigCRy)
 		witness.SignatureCpts[2].S1.Assign(sigCS1)
 		witness.SignatureCpts[2].S2.Assign(sigCS2)
 
+		if i == 0 {
+			// the winner reported to the regulator is one of the dealers asked, in the slot of the accepted quote
+			outsider, err := keystore.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			outsiderSigned, err := NewLocalSigner(outsider, nil).SignQuote(testCase.bond, rfqID, new(big.Int).SetBytes(testCase.acceptedQuote))
+			if err != nil {
+				t.Fatal(err)
+			}
+			outsiderReport := report
+			outsiderReport.Dealer = outsider.Public()
+			outsiderCiphertext, err := outsiderReport.Encrypt(pubKeyRegulator, nonce)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bad := witness
+			bad.AcceptedQuotePubKey = PublicKey{}
+			assignPublicKey(&bad.AcceptedQuotePubKey, outsider.Public().Bytes())
+			bad.AcceptedQuoteSigned = Signature{}
+			if err = assignSignature(&bad.AcceptedQuoteSigned, outsiderSigned.Signature); err != nil {
+				t.Fatal(err)
+			}
+			bad.RegulatorReport = Ciphertext{}
+			bad.RegulatorReport.Assign(&outsiderCiphertext)
+			if groth16.IsSolved(r1cs, &bad) == nil {
+				t.Fatal("a key outside the dealers is reported as the winner")
+			}
+
+			bad = witness
+			bad.WinnerIndex = frontend.Variable{}
+			bad.WinnerIndex.Assign(2)
+			if groth16.IsSolved(r1cs, &bad) == nil {
+				t.Fatal("the winner is not in the slot of the accepted quote")
+			}
+		}
+
 		// Generate Proof
 		proof, err := groth16.Prove(r1cs, pk, &witness)
 
-		if err != nil {
-			fmt.Println("Test", i, "fails")
-
+		if testCase.proofFails {
+			if err == nil {
+				t.Fatal("Test", i, "proves:", testCase.message)
+			}
+			fmt.Println("Test", i, "fails as expected")
+		} else if err != nil {
+			t.Fatal("Test", i, "fails:", err)
 		} else {
 
 			//Check with a correct value and it returns NIL
@@ -231,8 +360,7 @@ func TestBondv(t *testing.T) {
 			IsinHash = testCase.bondHash
 			witnessCorrectValue.Bond.Assign(IsinHash)
 
-			AcceptedQuoteSigned, err = privKeyCpt1.Sign(testCase.acceptedQuote[:], hFunc)
-			sigRx, sigRy, sigS1, sigS2 = parseSignature(id, AcceptedQuoteSigned)
+			sigRx, sigRy, sigS1, sigS2, err = parseSignature(id, AcceptedQuoteSigned)
 			witnessCorrectValue.AcceptedQuoteSigned.R.X.Assign(sigRx)
 			witnessCorrectValue.AcceptedQuoteSigned.R.Y.Assign(sigRy)
 			witnessCorrectValue.AcceptedQuoteSigned.S1.Assign(sigS1)
@@ -247,9 +375,41 @@ func TestBondv(t *testing.T) {
 			witnessCorrectValue.PublicKeyCpts[2].A.X.Assign(pubkeyCAx)
 			witnessCorrectValue.PublicKeyCpts[2].A.Y.Assign(pubkeyCAy)
 
+			witnessCorrectValue.RegulatorKey.A.X.Assign(regulatorx)
+			witnessCorrectValue.RegulatorKey.A.Y.Assign(regulatory)
+			witnessCorrectValue.RegulatorReport.Assign(&reportCiphertext)
+
+			assignDate(&witnessCorrectValue.SettlementDate, settlementDate)
+			witnessCorrectValue.SettlementAmount.Assign(settlementAmount)
+			witnessCorrectValue.InstrumentType.Assign(int(testCase.bond.Type))
+			for j := range commitments {
+				witnessCorrectValue.QuoteCommitments[j].Assign(commitments[j])
+			}
+			witnessCorrectValue.InitiatorKey.A.X.Assign(initiatorx)
+			witnessCorrectValue.InitiatorKey.A.Y.Assign(initiatory)
+			witnessCorrectValue.ReceiptCount.Assign(len(commitments))
+			witnessCorrectValue.QuoteCount.Assign(len(commitments))
+			witnessCorrectValue.MinQuotes.Assign(3)
+			witnessCorrectValue.RFQID.Assign(rfqID)
+			for j := range nullifiers {
+				witnessCorrectValue.Nullifiers[j].Assign(nullifiers[j])
+			}
+			witnessCorrectValue.ReferenceKey.A.X.Assign(publisherx)
+			witnessCorrectValue.ReferenceKey.A.Y.Assign(publishery)
+			witnessCorrectValue.ReferenceTime.Assign(big.NewInt(executionTime.Unix()))
+			witnessCorrectValue.ToleranceBp.Assign(toleranceBp)
+
 			err = groth16.Verify(proof, vk, &witnessCorrectValue)
 			if err != nil {
-				fmt.Print(err)
+				t.Fatal("Test", i, "doesn't verify:", err)
+			}
+
+			// the proof is accepted once, the same quotes can't be submitted again
+			if err = registry.Nullify(nullifiers[:]...); err != nil {
+				t.Fatal(err)
+			}
+			if err = registry.Nullify(nullifiers[:]...); err == nil {
+				t.Fatal("proof accepted twice")
 			}
 
 			/*Part that is used in a smart contract */
@@ -257,7 +417,7 @@ func TestBondv(t *testing.T) {
 				a     [2]*big.Int
 				b     [2][2]*big.Int
 				c     [2]*big.Int
-				input [12]*big.Int
+				input [37 + tradeReportSize]*big.Int
 			)
 
 			// get proof bytes
@@ -284,6 +444,22 @@ func TestBondv(t *testing.T) {
 			7,8 - PublicKeyCpt2       PublicKey         `gnark:",public"`  // Public key to check quotes signed
 			9,10  - PublicKeyCpt3       PublicKey         `gnark:",public"`  // Public key to check quotes signed
 			11 - Bond                frontend.Variable `gnark:",public"`  // hash of Isin, Ticker and Size
+			12,13 - RegulatorKey        PublicKey         `gnark:",public"`  // Public key of the regulator
+			14,15 - RegulatorReport.C1  Point             `gnark:",public"`  // ElGamal r*G
+			16..31 - RegulatorReport.Data                 `gnark:",public"`  // encrypted quote, winner and bond
+			32,33,34 - SettlementDate   [3]Variable       `gnark:",public"`  // year, month, day
+			35 - SettlementAmount       Variable          `gnark:",public"`  // accepted quote plus accrued interest
+			36 - InstrumentType         Variable          `gnark:",public"`  // Vanilla, Callable, ..., StepUp
+			37,38,39 - QuoteCommitments [3]Variable       `gnark:",public"`  // sealed quotes of the dealers
+			40,41 - InitiatorKey        PublicKey         `gnark:",public"`  // signs the receipts of the quotes
+			42 - ReceiptCount           Variable          `gnark:",public"`  // number of quotes acknowledged
+			43 - QuoteCount             Variable          `gnark:",public"`  // dealers who quoted rather than passed
+			44 - MinQuotes              Variable          `gnark:",public"`  // fewest competing quotes accepted
+			45 - RFQID                  Variable          `gnark:",public"`  // unique to the RFQ
+			46,47,48 - Nullifiers       [3]Variable       `gnark:",public"`  // recorded by the registry contract
+			49,50 - ReferenceKey        PublicKey         `gnark:",public"`  // signs the reference price
+			51 - ReferenceTime          Variable          `gnark:",public"`  // execution time, unix seconds
+			52 - ToleranceBp            Variable          `gnark:",public"`  // best execution band, in basis points
 			*/
 			input[0] = new(big.Int).SetBytes(testCase.acceptedQuote)
 			input[1] = new(big.Int).SetBytes(sigRx)
@@ -302,8 +478,39 @@ func TestBondv(t *testing.T) {
 
 			input[11] = new(big.Int).SetBytes(IsinHash)
 
+			input[12] = new(big.Int).SetBytes(regulatorx)
+			input[13] = new(big.Int).SetBytes(regulatory)
+
+			input[14] = reportCiphertext.C1.X.ToBigIntRegular(new(big.Int))
+			input[15] = reportCiphertext.C1.Y.ToBigIntRegular(new(big.Int))
+			for j := 0; j < tradeReportSize; j++ {
+				input[16+j] = reportCiphertext.Data[j].ToBigIntRegular(new(big.Int))
+			}
+
+			input[16+tradeReportSize] = big.NewInt(int64(settlementDate.Year()))
+			input[17+tradeReportSize] = big.NewInt(int64(settlementDate.Month()))
+			input[18+tradeReportSize] = big.NewInt(int64(settlementDate.Day()))
+			input[19+tradeReportSize] = settlementAmount
+			input[20+tradeReportSize] = big.NewInt(int64(testCase.bond.Type))
+			for j := range commitments {
+				input[21+tradeReportSize+j] = new(big.Int).SetBytes(commitments[j])
+			}
+			input[24+tradeReportSize] = new(big.Int).SetBytes(initiatorx)
+			input[25+tradeReportSize] = new(big.Int).SetBytes(initiatory)
+			input[26+tradeReportSize] = big.NewInt(int64(len(commitments)))
+			input[27+tradeReportSize] = big.NewInt(int64(len(commitments)))
+			input[28+tradeReportSize] = big.NewInt(3)
+			input[29+tradeReportSize] = rfqID
+			for j := range nullifiers {
+				input[30+tradeReportSize+j] = new(big.Int).SetBytes(nullifiers[j])
+			}
+			input[33+tradeReportSize] = new(big.Int).SetBytes(publisherx)
+			input[34+tradeReportSize] = new(big.Int).SetBytes(publishery)
+			input[35+tradeReportSize] = big.NewInt(executionTime.Unix())
+			input[36+tradeReportSize] = big.NewInt(toleranceBp)
+
 			/*Printing here so we can test values on a deployed smart contract */
-			for j := 0; j < 12; j++ {
+			for j := 0; j < len(input); j++ {
 				fmt.Println(input[j])
 			}
 
@@ -0,0 +1,127 @@
+// Package bondmath computes coupon schedules, accrued interest and settlement amounts of bonds.
+package bondmath
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errFrequency     = errors.New("coupon frequency must divide 12")
+	errAfterMaturity = errors.New("settlement date is not before maturity")
+)
+
+var one100 = decimal.NewFromInt(100)
+
+// Coupon holds the terms of a coupon
+type Coupon struct {
+	Rate      decimal.Decimal // annual rate in percent: 5.375, the initial rate of a step-up bond or the current rate of a floater
+	Frequency int             // number of coupons per year
+	DayCount  DayCount
+	Maturity  time.Time
+	Steps     StepSchedule  // rate changes of a step-up bond
+	Floating  *FloatingRate // reference index and spread of a floating rate note
+}
+
+// PerpetualYear is the maturity year of perpetual bonds: their coupon dates are computed back from
+// a maturity in the last supported year, on the month and day of a coupon date
+const PerpetualYear = 9999
+
+// PerpetualMaturity returns the maturity used for the coupon schedule of a perpetual bond paying on couponDate
+func PerpetualMaturity(couponDate time.Time) time.Time {
+	return time.Date(PerpetualYear, couponDate.Month(), couponDate.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodMonths returns the number of months between two coupon dates
+func (c *Coupon) PeriodMonths() (int, error) {
+	if c.Frequency <= 0 || 12%c.Frequency != 0 {
+		return 0, errFrequency
+	}
+	return 12 / c.Frequency, nil
+}
+
+// CouponDate returns the coupon date n periods before maturity.
+// The maturity day is kept, or the last day of the month when it doesn't exist.
+func (c *Coupon) CouponDate(n int) (time.Time, error) {
+	months, err := c.PeriodMonths()
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := c.Maturity.Date()
+
+	// first day of the target month, then clamp the day
+	first := time.Date(y, m-time.Month(n*months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Schedule returns the coupon dates after from, up to and including maturity
+func (c *Coupon) Schedule(from time.Time) ([]time.Time, error) {
+	var dates []time.Time
+	for n := 0; ; n++ {
+		date, err := c.CouponDate(n)
+		if err != nil {
+			return nil, err
+		}
+		if !date.After(from) {
+			break
+		}
+		dates = append([]time.Time{date}, dates...)
+	}
+	return dates, nil
+}
+
+// Period returns the coupon dates around settle: previous <= settle < next,
+// and the number of periods from previous to maturity
+func (c *Coupon) Period(settle time.Time) (previous, next time.Time, periods int, err error) {
+	if DayNumber(settle) >= DayNumber(c.Maturity) {
+		return previous, next, 0, errAfterMaturity
+	}
+	next = c.Maturity
+	for periods = 1; ; periods++ {
+		previous, err = c.CouponDate(periods)
+		if err != nil {
+			return
+		}
+		if DayNumber(previous) <= DayNumber(settle) {
+			return
+		}
+		next = previous
+	}
+}
+
+// AccruedInterest returns the interest accrued on face since the last coupon date
+func (c *Coupon) AccruedInterest(face decimal.Decimal, settle time.Time) (decimal.Decimal, error) {
+	previous, next, _, err := c.Period(settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	num, den := c.DayCount.Accrual(previous, settle, next, c.Frequency)
+
+	// face * rate% * num / den, with the rate set at the start of the period
+	interest := face.Mul(c.RateOn(previous)).Mul(decimal.NewFromInt(num))
+	return interest.Div(one100.Mul(decimal.NewFromInt(den))), nil
+}
+
+// DirtyPrice returns the price per 100 of face including accrued interest
+func (c *Coupon) DirtyPrice(cleanPrice decimal.Decimal, settle time.Time) (decimal.Decimal, error) {
+	accrued, err := c.AccruedInterest(one100, settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return cleanPrice.Add(accrued), nil
+}
+
+// SettlementAmount returns clean price (per 100) * face / 100 + accrued interest
+func (c *Coupon) SettlementAmount(cleanPrice, face decimal.Decimal, settle time.Time) (decimal.Decimal, error) {
+	accrued, err := c.AccruedInterest(face, settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return cleanPrice.Div(one100).Mul(face).Add(accrued), nil
+}
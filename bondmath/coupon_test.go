@@ -0,0 +1,169 @@
+package bondmath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDays30360(t *testing.T) {
+	cases := []struct {
+		start, end time.Time
+		days       int64
+	}{
+		{date(2021, 9, 27), date(2021, 11, 23), 56},
+		{date(2021, 1, 31), date(2021, 3, 31), 60},
+		{date(2021, 1, 15), date(2021, 3, 31), 76},
+		{date(2021, 2, 28), date(2021, 3, 31), 33},
+		{date(2020, 12, 23), date(2021, 6, 23), 180},
+	}
+	for _, c := range cases {
+		if days := Days30360(c.start, c.end); days != c.days {
+			t.Errorf("30/360 from %v to %v: got %d, expected %d", c.start, c.end, days, c.days)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	coupon := Coupon{Rate: decimal.RequireFromString("3.125"), Frequency: 2, Maturity: date(2025, 1, 23)}
+	dates, err := coupon.Schedule(date(2023, 6, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{date(2023, 7, 23), date(2024, 1, 23), date(2024, 7, 23), date(2025, 1, 23)}
+	if len(dates) != len(expected) {
+		t.Fatal("wrong schedule", dates)
+	}
+	for i := range dates {
+		if !dates[i].Equal(expected[i]) {
+			t.Fatal("wrong schedule", dates)
+		}
+	}
+
+	// end of month maturities are clamped
+	coupon = Coupon{Frequency: 4, Maturity: date(2024, 5, 31)}
+	previous, err := coupon.CouponDate(1)
+	if err != nil || !previous.Equal(date(2024, 2, 29)) {
+		t.Fatal("wrong coupon date", previous, err)
+	}
+
+	coupon.Frequency = 5
+	if _, err := coupon.Schedule(date(2023, 6, 1)); err == nil {
+		t.Fatal("a frequency that doesn't divide 12 should fail")
+	}
+}
+
+func TestAccruedInterest(t *testing.T) {
+	settle := date(2021, 11, 23)
+	cases := []struct {
+		coupon  Coupon
+		face    string
+		accrued string
+	}{
+		// 550000 * 5.375% * 56 / 360
+		{Coupon{Rate: decimal.RequireFromString("5.375"), Frequency: 2, DayCount: Thirty360, Maturity: date(2027, 9, 27)}, "550000", "4598.6111111111111111"},
+		// 600000 * 1.5% * 80 / 360
+		{Coupon{Rate: decimal.RequireFromString("1.5"), Frequency: 4, DayCount: Actual360, Maturity: date(2024, 3, 4)}, "600000", "2000"},
+		// 100 * 3.125% * 123 / (2 * 184)
+		{Coupon{Rate: decimal.RequireFromString("3.125"), Frequency: 2, DayCount: ActualActual, Maturity: date(2025, 1, 23)}, "100", "1.0444972826086957"},
+	}
+	for _, c := range cases {
+		accrued, err := c.coupon.AccruedInterest(decimal.RequireFromString(c.face), settle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !accrued.Equal(decimal.RequireFromString(c.accrued)) {
+			t.Errorf("%v: got %v, expected %v", c.coupon.DayCount, accrued, c.accrued)
+		}
+	}
+
+	coupon := cases[0].coupon
+	amount, err := coupon.SettlementAmount(decimal.RequireFromString("92.63"), decimal.NewFromInt(550000), settle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !amount.Equal(decimal.RequireFromString("514063.6111111111111111")) {
+		t.Fatal("wrong settlement amount", amount)
+	}
+
+	if _, err := coupon.AccruedInterest(decimal.NewFromInt(100), date(2027, 9, 27)); err == nil {
+		t.Fatal("settlement at maturity should fail")
+	}
+}
+
+func TestStepUp(t *testing.T) {
+	// JPM STEP 06/23/2030 stepping up to 2.25 on 06/23/2026
+	coupon := Coupon{Rate: decimal.RequireFromString("1.5"), Frequency: 2, DayCount: Thirty360, Maturity: date(2030, 6, 23),
+		Steps: StepSchedule{{Date: date(2026, 6, 23), Rate: decimal.RequireFromString("2.25")}}}
+
+	if !coupon.RateOn(date(2026, 6, 22)).Equal(decimal.RequireFromString("1.5")) {
+		t.Fatal("rate before the step should be the initial rate")
+	}
+	if !coupon.RateOn(date(2026, 6, 23)).Equal(decimal.RequireFromString("2.25")) {
+		t.Fatal("rate on the step date should be the step rate")
+	}
+
+	// 100 * 2.25% * 60 / 360
+	accrued, err := coupon.AccruedInterest(decimal.NewFromInt(100), date(2026, 8, 23))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !accrued.Equal(decimal.RequireFromString("0.375")) {
+		t.Fatal("wrong accrued interest after the step", accrued)
+	}
+
+	// a step-up bond at par yields more than its initial coupon
+	ytm, err := coupon.YieldToMaturity(decimal.NewFromInt(100), date(2021, 6, 23))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ytm.GreaterThan(coupon.Rate) || !ytm.LessThan(decimal.RequireFromString("2.25")) {
+		t.Fatal("wrong yield to maturity", ytm)
+	}
+}
+
+func TestFloatingRate(t *testing.T) {
+	// The Toronto-Dominion VAR 03/04/2024, quarterly SOFR + 48bp
+	coupon := Coupon{Rate: decimal.RequireFromString("0.5125"), Frequency: 4, DayCount: Actual360, Maturity: date(2024, 3, 4),
+		Floating: &FloatingRate{Index: "SOFR", Spread: decimal.RequireFromString("0.48")}}
+
+	reset, err := coupon.ResetDate(date(2021, 11, 23))
+	if err != nil || !reset.Equal(date(2021, 9, 4)) {
+		t.Fatal("wrong reset date", reset, err)
+	}
+
+	resets, err := coupon.ResetDates(date(2023, 3, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{date(2023, 6, 4), date(2023, 9, 4), date(2023, 12, 4)}
+	if len(resets) != len(expected) {
+		t.Fatal("wrong reset dates", resets)
+	}
+	for i := range resets {
+		if !resets[i].Equal(expected[i]) {
+			t.Fatal("wrong reset dates", resets)
+		}
+	}
+
+	rate, err := coupon.Reset(Fixing{Index: "SOFR", Date: date(2021, 9, 4), Rate: decimal.RequireFromString("0.0325")})
+	if err != nil || !rate.Equal(decimal.RequireFromString("0.5125")) {
+		t.Fatal("wrong reset rate", rate, err)
+	}
+	if _, err := coupon.Reset(Fixing{Index: "LIBOR3M", Date: date(2021, 9, 4)}); err == nil {
+		t.Fatal("a fixing of another index should fail")
+	}
+	if _, err := coupon.Reset(Fixing{Index: "SOFR", Date: date(2021, 9, 5)}); err == nil {
+		t.Fatal("a fixing off the reset dates should fail")
+	}
+
+	coupon.Floating = nil
+	if _, err := coupon.ResetDate(date(2021, 11, 23)); err == nil {
+		t.Fatal("a fixed coupon has no reset date")
+	}
+}
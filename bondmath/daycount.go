@@ -0,0 +1,71 @@
+package bondmath
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DayCount is the convention used to measure the accrual fraction of a coupon period
+type DayCount int
+
+const (
+	// Thirty360 is 30/360 US bond basis
+	Thirty360 DayCount = iota
+	// Actual360 counts actual days over a 360 days year
+	Actual360
+	// ActualActual is ACT/ACT ICMA: actual days over the actual length of the coupon period
+	ActualActual
+)
+
+var dayCountNames = [...]string{"30/360", "ACT/360", "ACT/ACT"}
+
+func (dc DayCount) String() string {
+	if dc < 0 || int(dc) >= len(dayCountNames) {
+		return fmt.Sprintf("DayCount(%d)", int(dc))
+	}
+	return dayCountNames[dc]
+}
+
+// ParseDayCount reads a day count convention as written by String
+func ParseDayCount(s string) (DayCount, error) {
+	for i, name := range dayCountNames {
+		if strings.EqualFold(s, name) {
+			return DayCount(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day count convention %q", s)
+}
+
+// Accrual returns the fraction of the coupon period from start to settle as num/den of an annual coupon.
+// start and end are the coupon dates around settle, frequency the number of coupons per year.
+func (dc DayCount) Accrual(start, settle, end time.Time, frequency int) (num, den int64) {
+	switch dc {
+	case Thirty360:
+		return Days30360(start, settle), 360
+	case Actual360:
+		return DayNumber(settle) - DayNumber(start), 360
+	default:
+		return DayNumber(settle) - DayNumber(start), int64(frequency) * (DayNumber(end) - DayNumber(start))
+	}
+}
+
+// DayNumber returns the number of days since 1970-01-01
+func DayNumber(t time.Time) int64 {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// Days30360 counts the days from start to end with the 30/360 US bond basis rules
+func Days30360(start, end time.Time) int64 {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return 360*int64(y2-y1) + 30*int64(m2-m1) + int64(d2-d1)
+}
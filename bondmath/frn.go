@@ -0,0 +1,72 @@
+package bondmath
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errNotFloating  = errors.New("coupon is not floating")
+	errFixingIndex  = errors.New("fixing is not of the reference index")
+	errNotResetDate = errors.New("fixing date is not a reset date")
+	errNegativeRate = errors.New("floating coupon rate is negative")
+)
+
+// FloatingRate holds the terms of a floating rate note. The coupon of a period is the fixing of
+// Index on the reset date plus Spread. Coupons are reset in advance, on every coupon date before maturity.
+type FloatingRate struct {
+	Index  string          // reference rate index: "SOFR", "CDOR3M"
+	Spread decimal.Decimal // added to the fixing, in percent: 0.59 for 59bp
+}
+
+// Fixing is the rate of a reference index published for a date, in percent
+type Fixing struct {
+	Index string
+	Date  time.Time
+	Rate  decimal.Decimal
+}
+
+// ResetDate returns the date the coupon of the period containing date is fixed on
+func (c *Coupon) ResetDate(date time.Time) (time.Time, error) {
+	if c.Floating == nil {
+		return time.Time{}, errNotFloating
+	}
+	previous, _, _, err := c.Period(date)
+	return previous, err
+}
+
+// ResetDates returns the reset dates after from, up to the last coupon date before maturity
+func (c *Coupon) ResetDates(from time.Time) ([]time.Time, error) {
+	if c.Floating == nil {
+		return nil, errNotFloating
+	}
+	dates, err := c.Schedule(from)
+	if err != nil || len(dates) == 0 {
+		return nil, err
+	}
+	return dates[:len(dates)-1], nil
+}
+
+// Reset returns the coupon rate of the period starting on the date of fixing: its rate plus the spread
+func (c *Coupon) Reset(fixing Fixing) (decimal.Decimal, error) {
+	if c.Floating == nil {
+		return decimal.Zero, errNotFloating
+	}
+	if fixing.Index != c.Floating.Index {
+		return decimal.Zero, errFixingIndex
+	}
+	reset, err := c.ResetDate(fixing.Date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if DayNumber(reset) != DayNumber(fixing.Date) {
+		return decimal.Zero, errNotResetDate
+	}
+	rate := fixing.Rate.Add(c.Floating.Spread)
+	if rate.Sign() < 0 {
+		return decimal.Zero, errNegativeRate
+	}
+	return rate, nil
+}
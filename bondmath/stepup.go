@@ -0,0 +1,29 @@
+package bondmath
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Step changes the coupon rate to Rate (in percent) from Date on
+type Step struct {
+	Date time.Time
+	Rate decimal.Decimal
+}
+
+// StepSchedule lists the coupon steps of a step-up bond, in date order
+type StepSchedule []Step
+
+// RateOn returns the coupon rate applicable on date: the rate of the last step on or before date,
+// or the initial rate
+func (c *Coupon) RateOn(date time.Time) decimal.Decimal {
+	rate := c.Rate
+	for _, step := range c.Steps {
+		if DayNumber(step.Date) > DayNumber(date) {
+			break
+		}
+		rate = step.Rate
+	}
+	return rate
+}
@@ -0,0 +1,158 @@
+package bondmath
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errNotCouponDate = errors.New("redemption date is not a coupon date")
+	errNoYield       = errors.New("yield doesn't converge")
+)
+
+// Call is the right of the issuer to redeem the bond at Price (per 100) on Date
+type Call struct {
+	Date  time.Time
+	Price decimal.Decimal
+}
+
+// CallSchedule lists the call dates of a callable bond
+type CallSchedule []Call
+
+// Put is the right of the holder to sell the bond back to the issuer at Price (per 100) on Date
+type Put struct {
+	Date  time.Time
+	Price decimal.Decimal
+}
+
+// PutSchedule lists the put dates of a puttable bond
+type PutSchedule []Put
+
+// YieldTo returns the yield, in percent, of a bond bought at cleanPrice (per 100) on settle
+// and redeemed at redemptionPrice (per 100) on redemptionDate, which must be a coupon date.
+// Cash flows are discounted with the coupon frequency as compounding frequency.
+func (c *Coupon) YieldTo(cleanPrice decimal.Decimal, settle, redemptionDate time.Time, redemptionPrice decimal.Decimal) (decimal.Decimal, error) {
+	previous, next, _, err := c.Period(settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	schedule, err := c.Schedule(settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	n := -1
+	for i, date := range schedule {
+		if DayNumber(date) == DayNumber(redemptionDate) {
+			n = i
+		}
+	}
+	if n < 0 {
+		return decimal.Zero, errNotCouponDate
+	}
+
+	dirty, err := c.DirtyPrice(cleanPrice, settle)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	target, _ := dirty.Float64()
+
+	// coupons paid on the schedule dates, at the rate set at the start of each period
+	coupons := make([]float64, n+1)
+	for k := 0; k <= n; k++ {
+		start := previous
+		if k > 0 {
+			start = schedule[k-1]
+		}
+		coupons[k], _ = c.RateOn(start).Div(decimal.NewFromInt(int64(c.Frequency))).Float64()
+	}
+	redemption, _ := redemptionPrice.Float64()
+	frequency := float64(c.Frequency)
+
+	// fraction of the current period left until the next coupon
+	w := float64(DayNumber(next)-DayNumber(settle)) / float64(DayNumber(next)-DayNumber(previous))
+
+	price := func(yield float64) float64 {
+		pv := 0.0
+		for k := 0; k <= n; k++ {
+			pv += coupons[k] / math.Pow(1+yield/frequency, w+float64(k))
+		}
+		return pv + redemption/math.Pow(1+yield/frequency, w+float64(n))
+	}
+
+	// the price decreases with the yield
+	low, high := -0.99*frequency, 1.0
+	for high-low > 1e-12 {
+		mid := (low + high) / 2
+		if price(mid) > target {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	if math.Abs(price(low)-target) > 1e-6 {
+		return decimal.Zero, errNoYield
+	}
+	return decimal.NewFromFloat(low * 100).Round(6), nil
+}
+
+// YieldToMaturity returns the yield in percent of a bond redeemed at par on maturity
+func (c *Coupon) YieldToMaturity(cleanPrice decimal.Decimal, settle time.Time) (decimal.Decimal, error) {
+	return c.YieldTo(cleanPrice, settle, c.Maturity, one100)
+}
+
+// YieldToCall returns the yield in percent of a bond redeemed on call
+func (c *Coupon) YieldToCall(cleanPrice decimal.Decimal, settle time.Time, call Call) (decimal.Decimal, error) {
+	return c.YieldTo(cleanPrice, settle, call.Date, call.Price)
+}
+
+// YieldToPut returns the yield in percent of a bond sold back to the issuer on put
+func (c *Coupon) YieldToPut(cleanPrice decimal.Decimal, settle time.Time, put Put) (decimal.Decimal, error) {
+	return c.YieldTo(cleanPrice, settle, put.Date, put.Price)
+}
+
+// YieldToWorst returns the lowest of the yield to maturity and the yields to the calls after settle,
+// and the redemption date giving it
+func (c *Coupon) YieldToWorst(cleanPrice decimal.Decimal, settle time.Time, calls CallSchedule) (decimal.Decimal, time.Time, error) {
+	worst, err := c.YieldToMaturity(cleanPrice, settle)
+	if err != nil {
+		return decimal.Zero, time.Time{}, err
+	}
+	workout := c.Maturity
+
+	for _, call := range calls {
+		if DayNumber(call.Date) <= DayNumber(settle) {
+			continue
+		}
+		yield, err := c.YieldToCall(cleanPrice, settle, call)
+		if err != nil {
+			return decimal.Zero, time.Time{}, err
+		}
+		if yield.LessThan(worst) {
+			worst, workout = yield, call.Date
+		}
+	}
+	return worst, workout, nil
+}
+
+// RankByYieldToWorst returns the indexes of the prices from the highest yield to worst to the lowest,
+// which is the best quote first for a buyer. Equal yields keep the order of the quotes.
+func (c *Coupon) RankByYieldToWorst(prices []decimal.Decimal, settle time.Time, calls CallSchedule) ([]int, error) {
+	yields := make([]decimal.Decimal, len(prices))
+	ranking := make([]int, len(prices))
+	for i, price := range prices {
+		yield, _, err := c.YieldToWorst(price, settle, calls)
+		if err != nil {
+			return nil, err
+		}
+		yields[i] = yield
+		ranking[i] = i
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return yields[ranking[i]].GreaterThan(yields[ranking[j]])
+	})
+	return ranking, nil
+}
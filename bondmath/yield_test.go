@@ -0,0 +1,100 @@
+package bondmath
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestYieldToWorst(t *testing.T) {
+	// JPM 3.125% 01/23/2025 callable at par on 01/23/2024
+	coupon := Coupon{Rate: decimal.RequireFromString("3.125"), Frequency: 2, DayCount: Thirty360, Maturity: date(2025, 1, 23)}
+	calls := CallSchedule{{Date: date(2024, 1, 23), Price: decimal.NewFromInt(100)}}
+
+	// at par on a coupon date the yield is the coupon
+	ytm, err := coupon.YieldToMaturity(decimal.NewFromInt(100), date(2021, 7, 23))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ytm.Equal(decimal.RequireFromString("3.125")) {
+		t.Fatal("wrong yield to maturity", ytm)
+	}
+
+	// a premium bond is worst when called
+	settle := date(2021, 11, 23)
+	premium := decimal.NewFromInt(104)
+	ytm, _ = coupon.YieldToMaturity(premium, settle)
+	ytc, err := coupon.YieldToCall(premium, settle, calls[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ytw, workout, err := coupon.YieldToWorst(premium, settle, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ytc.LessThan(ytm) || !ytw.Equal(ytc) || !workout.Equal(calls[0].Date) {
+		t.Fatal("yield to worst should be the yield to call", ytm, ytc, ytw, workout)
+	}
+
+	// a discount bond is worst at maturity
+	discount := decimal.RequireFromString("92.63")
+	ytm, _ = coupon.YieldToMaturity(discount, settle)
+	ytw, workout, _ = coupon.YieldToWorst(discount, settle, calls)
+	if !ytw.Equal(ytm) || !workout.Equal(coupon.Maturity) {
+		t.Fatal("yield to worst should be the yield to maturity", ytm, ytw, workout)
+	}
+
+	// calls must be on coupon dates
+	if _, err := coupon.YieldToCall(premium, settle, Call{Date: date(2024, 2, 1), Price: decimal.NewFromInt(100)}); err == nil {
+		t.Fatal("call between coupon dates should fail")
+	}
+}
+
+func TestRankByYieldToWorst(t *testing.T) {
+	coupon := Coupon{Rate: decimal.RequireFromString("3.125"), Frequency: 2, DayCount: Thirty360, Maturity: date(2025, 1, 23)}
+	calls := CallSchedule{{Date: date(2024, 1, 23), Price: decimal.NewFromInt(100)}}
+
+	prices := []decimal.Decimal{
+		decimal.RequireFromString("101.5"),
+		decimal.RequireFromString("99.75"),
+		decimal.RequireFromString("104"),
+		decimal.RequireFromString("99.75"),
+	}
+	ranking, err := coupon.RankByYieldToWorst(prices, date(2021, 11, 23), calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{1, 3, 0, 2}
+	for i := range expected {
+		if ranking[i] != expected[i] {
+			t.Fatal("wrong ranking", ranking)
+		}
+	}
+}
+
+func TestYieldToPut(t *testing.T) {
+	coupon := Coupon{Rate: decimal.RequireFromString("3"), Frequency: 2, DayCount: Thirty360, Maturity: date(2031, 6, 15)}
+	put := Put{Date: date(2024, 6, 15), Price: decimal.NewFromInt(100)}
+	settle := date(2021, 6, 15)
+
+	// below par, the put shortens the time to the redemption gain
+	price := decimal.RequireFromString("95")
+	ytp, err := coupon.YieldToPut(price, settle, put)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ytm, err := coupon.YieldToMaturity(price, settle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ytp.GreaterThan(ytm) {
+		t.Fatal("yield to put should be higher than yield to maturity", ytp, ytm)
+	}
+
+	// a perpetual pays coupons back from its maturity in the last supported year
+	perpetual := Coupon{Rate: decimal.RequireFromString("4"), Frequency: 4, DayCount: Thirty360, Maturity: PerpetualMaturity(date(2021, 3, 15))}
+	previous, _, _, err := perpetual.Period(date(2021, 11, 23))
+	if err != nil || !previous.Equal(date(2021, 9, 15)) {
+		t.Fatal("wrong perpetual coupon period", previous, err)
+	}
+}
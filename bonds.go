@@ -0,0 +1,168 @@
+package financial
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"bloconuts/v0/bondmath"
+	"bloconuts/v0/secid"
+
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/shopspring/decimal"
+)
+
+// bondFieldsSize is the number of field elements used to encode a Bond:
+// Isin, Size, the Ticker split in two chunks, then the coupon rate, frequency,
+// day count, maturity year, month and day, the instrument type and the terms of that type
+const bondFieldsSize = 13
+
+// position of the instrument type and of its terms in the bond fields
+const (
+	typeField  = 10
+	termsField = 11
+)
+
+// rateDecimals is the number of decimals kept from a coupon rate in percent: 5.375 is encoded as 53750
+const rateDecimals = 4
+
+// a field element holds 31 bytes of text without overflowing the BN254 modulus
+const textChunkSize = 31
+
+var (
+	errTickerTooLong = errors.New("ticker does not fit in two field elements")
+	errInvalidSize   = errors.New("bond size is not an integer")
+	errInvalidRate   = errors.New("coupon rate has too many decimals")
+	errIndexTooLong  = errors.New("reference index does not fit in a field element")
+	errInvalidSpread = errors.New("spread must be positive with at most 4 decimals")
+
+	errInvalidCallPrice = errors.New("call price must be positive with at most 4 decimals")
+)
+
+// Bond has an Isin, size, ticker, the terms of its coupon and the terms of its instrument type
+type Bond struct {
+	Isin   string
+	Size   string
+	Ticker string
+	Type   InstrumentType
+	Coupon bondmath.Coupon
+	Calls  bondmath.CallSchedule // callable, perpetual and step-up bonds
+	Puts   bondmath.PutSchedule  // puttable bonds
+}
+
+// Fields encodes the bond as field elements so the attributes can be used inside a circuit.
+// Text is read as a big endian number, Size as a base 10 integer. Isin must be a valid ISIN.
+func (bond *Bond) Fields() ([bondFieldsSize]*big.Int, error) {
+	fields, err := bond.attributeFields()
+	if err != nil {
+		return [bondFieldsSize]*big.Int{}, err
+	}
+	terms, err := bond.terms()
+	if err != nil {
+		return [bondFieldsSize]*big.Int{}, err
+	}
+	copy(fields[termsField:], terms[:])
+	return fields, nil
+}
+
+// attributeFields encodes the fields of the bond before its terms, which are left nil
+func (bond *Bond) attributeFields() ([bondFieldsSize]*big.Int, error) {
+	var fields [bondFieldsSize]*big.Int
+
+	if err := secid.ValidateISIN(bond.Isin); err != nil {
+		return fields, fmt.Errorf("isin %q: %w", bond.Isin, err)
+	}
+	if len(bond.Ticker) > 2*textChunkSize {
+		return fields, errTickerTooLong
+	}
+
+	size, ok := new(big.Int).SetString(bond.Size, 10)
+	if !ok || size.Sign() < 0 {
+		return fields, errInvalidSize
+	}
+
+	rate := bond.Coupon.Rate.Shift(rateDecimals)
+	if !rate.Equal(rate.Truncate(0)) || rate.Sign() < 0 {
+		return fields, errInvalidRate
+	}
+
+	ticker := []byte(bond.Ticker)
+	split := len(ticker)
+	if split > textChunkSize {
+		split = textChunkSize
+	}
+
+	fields[0] = new(big.Int).SetBytes([]byte(bond.Isin))
+	fields[1] = size
+	fields[2] = new(big.Int).SetBytes(ticker[:split])
+	fields[3] = new(big.Int).SetBytes(ticker[split:])
+
+	year, month, day := bond.Coupon.Maturity.Date()
+	fields[4] = rate.BigInt()
+	fields[5] = big.NewInt(int64(bond.Coupon.Frequency))
+	fields[6] = big.NewInt(int64(bond.Coupon.DayCount))
+	fields[7] = big.NewInt(int64(year))
+	fields[8] = big.NewInt(int64(month))
+	fields[9] = big.NewInt(int64(day))
+	fields[typeField] = big.NewInt(int64(bond.Type))
+
+	return fields, nil
+}
+
+// CallScheduleHash returns the MiMC hash of the call dates (year, month, day) and prices
+func (bond *Bond) CallScheduleHash() ([]byte, error) {
+	calls := make([]*big.Int, 0, 4*len(bond.Calls))
+	for _, call := range bond.Calls {
+		price := call.Price.Shift(rateDecimals)
+		if !price.Equal(price.Truncate(0)) || price.Sign() <= 0 {
+			return nil, errInvalidCallPrice
+		}
+		year, month, day := call.Date.Date()
+		calls = append(calls, big.NewInt(int64(year)), big.NewInt(int64(month)), big.NewInt(int64(day)), price.BigInt())
+	}
+	return hashFields(calls...), nil
+}
+
+// bondFromFields is the inverse of Fields, except for the schedules which are only known by their hash
+func bondFromFields(fields [bondFieldsSize]*big.Int) *Bond {
+	ticker := append(fields[2].Bytes(), fields[3].Bytes()...)
+	bond := &Bond{
+		Isin:   string(fields[0].Bytes()),
+		Size:   fields[1].String(),
+		Ticker: string(ticker),
+		Type:   InstrumentType(fields[typeField].Int64()),
+		Coupon: bondmath.Coupon{
+			Rate:      decimal.NewFromBigInt(fields[4], -rateDecimals),
+			Frequency: int(fields[5].Int64()),
+			DayCount:  bondmath.DayCount(fields[6].Int64()),
+			Maturity: time.Date(int(fields[7].Int64()), time.Month(fields[8].Int64()), int(fields[9].Int64()),
+				0, 0, 0, 0, time.UTC),
+		},
+	}
+	var terms [bondTermsSize]*big.Int
+	copy(terms[:], fields[termsField:])
+	bond.setTerms(terms)
+	return bond
+}
+
+// Hash returns the MiMC hash of the bond fields. It is the value used as Bond in bondCircuit.
+func (bond *Bond) Hash() ([]byte, error) {
+	fields, err := bond.Fields()
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(fields[:]...), nil
+}
+
+// hashFields computes the MiMC hash of field elements the same way mimc.Hash does in a circuit:
+// every element is written as a 32 bytes big endian block
+func hashFields(elements ...*big.Int) []byte {
+	goMimc := hash.MIMC_BN254.New("seed")
+	for _, e := range elements {
+		var block [32]byte
+		e.FillBytes(block[:])
+		goMimc.Write(block[:])
+	}
+	return goMimc.Sum(nil)
+}
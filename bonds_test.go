@@ -0,0 +1,22 @@
+package financial
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+func TestBondIdentifier(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	bond := &Bond{Isin: "US46625HKC33", Size: "625000", Ticker: "JPM 3.125% 01/23/2025"}
+	_, err := bond.Hash()
+	assert.NoError(err)
+
+	// CUSIPs, bad check digits and truncated identifiers aren't hashed into the bond
+	for _, isin := range []string{"46625HKC3", "6625HKC3", "US46625HKC34", "US46625HKC3", ""} {
+		bond.Isin = isin
+		_, err := bond.Hash()
+		assert.Error(err, isin)
+	}
+}
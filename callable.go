@@ -0,0 +1,78 @@
+package financial
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// callableBondCircuit is the RFQ variant for callable bonds, where quotes are ranked by yield to worst.
+// The yield to worst of a bond decreases when its price increases, for any call schedule, so the ranking
+// by yield to worst (highest first) is the ranking of the quotes by price (smallest first).
+// The circuit proves that ranking for the call schedule disclosed by its hash, which is part of the bond hash.
+type callableBondCircuit struct {
+	Bond                frontend.Variable                 `gnark:",public"`  // hash of the bond attributes
+	CallScheduleHash    frontend.Variable                 `gnark:",public"`  // disclosed call schedule
+	PublicKeyCpts       [3]PublicKey                      `gnark:",public"`  // counterparties asked for a quote
+	Ranking             [3]frontend.Variable              `gnark:",public"`  // counterparty indexes, best yield to worst first
+	InstrumentType      frontend.Variable                 `gnark:",public"`  // Callable, Perpetual or StepUp
+	RFQID               frontend.Variable                 `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	BondAttributes      [bondFieldsSize]frontend.Variable `gnark:",private"` // as in Bond.Fields
+	QuoteFromCpts       [3]frontend.Variable              `gnark:",private"` // clean price * size, in cents
+	BondQuoteSignedCpts [3]Signature                      `gnark:",private"` // Sign(Bond hash, RFQID, quote)
+}
+
+func (circuit *callableBondCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	// the bond discloses its call schedule
+	cs.AssertIsEqual(circuit.Bond, mimc.Hash(cs, circuit.BondAttributes[:]...))
+	mustBeInstrumentType(cs, circuit.BondAttributes, circuit.InstrumentType)
+	cs.AssertIsEqual(circuit.CallScheduleHash, callScheduleTerm(cs, curveID, circuit.BondAttributes))
+
+	for i := 0; i < len(circuit.QuoteFromCpts); i++ {
+		// All quotes should be greater than zero
+		cs.AssertIsEqual(cs.IsZero(circuit.QuoteFromCpts[i], curveID), 0)
+
+		// the quote is valid only for that bond, that RFQ and the cpt
+		mustBeSignedQuote(cs, mimc, params, circuit.Bond, circuit.RFQID, circuit.QuoteFromCpts[i],
+			circuit.BondQuoteSignedCpts[i], circuit.PublicKeyCpts[i])
+	}
+
+	// Ranking is a permutation of the counterparties
+	for i := 0; i < len(circuit.Ranking); i++ {
+		for j := i + 1; j < len(circuit.Ranking); j++ {
+			cs.AssertIsEqual(cs.IsZero(cs.Sub(circuit.Ranking[i], circuit.Ranking[j]), curveID), 0)
+		}
+	}
+
+	// quotes in the ranking order are increasing
+	ranked := make([]frontend.Variable, len(circuit.Ranking))
+	for i := 0; i < len(circuit.Ranking); i++ {
+		ranked[i] = selectByIndex(cs, curveID, circuit.Ranking[i], circuit.QuoteFromCpts[:])
+	}
+	for i := 1; i < len(ranked); i++ {
+		cs.AssertIsLessOrEqual(ranked[i-1], ranked[i])
+	}
+
+	return nil
+}
+
+// selectByIndex returns values[index] and checks index is in range
+func selectByIndex(cs *frontend.ConstraintSystem, curveID ecc.ID, index frontend.Variable, values []frontend.Variable) frontend.Variable {
+	found := cs.Constant(0)
+	result := cs.Constant(0)
+	for j := 0; j < len(values); j++ {
+		isIndex := cs.IsZero(cs.Sub(index, j), curveID)
+		found = cs.Add(found, isIndex)
+		result = cs.Add(result, cs.Mul(isIndex, values[j]))
+	}
+	cs.AssertIsEqual(found, 1)
+	return result
+}
@@ -0,0 +1,119 @@
+package financial
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestCallableBondCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit callableBondCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	bond := &Bond{
+		Isin:   "US46625HKC33",
+		Size:   "625000",
+		Ticker: "JPM 3.125% 01/23/2025 Callable",
+		Type:   Callable,
+		Coupon: bondmath.Coupon{
+			Rate:      decimal.RequireFromString("3.125"),
+			Frequency: 2,
+			DayCount:  bondmath.Thirty360,
+			Maturity:  time.Date(2025, 1, 23, 0, 0, 0, 0, time.UTC),
+		},
+		Calls: bondmath.CallSchedule{
+			{Date: time.Date(2023, 1, 23, 0, 0, 0, 0, time.UTC), Price: decimal.RequireFromString("101")},
+			{Date: time.Date(2024, 1, 23, 0, 0, 0, 0, time.UTC), Price: decimal.NewFromInt(100)},
+		},
+	}
+	settle := time.Date(2021, 11, 23, 0, 0, 0, 0, time.UTC)
+	prices := []decimal.Decimal{
+		decimal.RequireFromString("101.5"),
+		decimal.RequireFromString("99.75"),
+		decimal.RequireFromString("104"),
+	}
+
+	ranking, err := bond.Coupon.RankByYieldToWorst(prices, settle, bond.Calls)
+	assert.NoError(err)
+	assert.Equal([]int{1, 0, 2}, ranking)
+
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+	bondFields, err := bond.Fields()
+	assert.NoError(err)
+	callScheduleHash, err := bond.CallScheduleHash()
+	assert.NoError(err)
+
+	size, _ := decimal.NewFromString(bond.Size)
+	hFunc := hash.MIMC_BN254.New("seed")
+
+	rfqID := big.NewInt(1)
+	var witness callableBondCircuit
+	witness.Bond.Assign(bondHash)
+	witness.RFQID.Assign(rfqID)
+	witness.CallScheduleHash.Assign(callScheduleHash)
+	witness.InstrumentType.Assign(int(bond.Type))
+	for i := range bondFields {
+		witness.BondAttributes[i].Assign(bondFields[i])
+	}
+	for i, price := range prices {
+		privKey, err := signature.EDDSA_BN254.New(rand.New(rand.NewSource(int64(i + 1))))
+		assert.NoError(err)
+
+		quote := price.Mul(size).BigInt()
+		bondQuoteHash := hashFields(new(big.Int).SetBytes(bondHash), rfqID, quote)
+		bondQuoteSigned, err := privKey.Sign(bondQuoteHash, hFunc)
+		assert.NoError(err)
+
+		assignPublicKey(&witness.PublicKeyCpts[i], privKey.Public().Bytes())
+		witness.QuoteFromCpts[i].Assign(quote)
+		assignSignature(&witness.BondQuoteSignedCpts[i], bondQuoteSigned)
+	}
+
+	good := witness
+	for i := range ranking {
+		good.Ranking[i].Assign(ranking[i])
+	}
+	assert.SolvingSucceeded(r1cs, &good)
+
+	// ranking the most expensive quote first doesn't match the yields to worst
+	bad := witness
+	for i, index := range []int{0, 1, 2} {
+		bad.Ranking[i].Assign(index)
+	}
+	assert.SolvingFailed(r1cs, &bad)
+
+	// the same quote can't be ranked twice
+	bad = witness
+	for i, index := range []int{1, 1, 0} {
+		bad.Ranking[i].Assign(index)
+	}
+	assert.SolvingFailed(r1cs, &bad)
+
+	// the disclosed call schedule must be the one of the bond
+	bad = good
+	bad.CallScheduleHash = frontend.Variable{}
+	bad.CallScheduleHash.Assign(hashFields(big.NewInt(2024), big.NewInt(1), big.NewInt(23), big.NewInt(1000000)))
+	assert.SolvingFailed(r1cs, &bad)
+
+	// the verifier knows the rules of a callable bond applied
+	bad = good
+	bad.InstrumentType = frontend.Variable{}
+	bad.InstrumentType.Assign(int(Puttable))
+	assert.SolvingFailed(r1cs, &bad)
+}
@@ -0,0 +1,206 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/shopspring/decimal"
+)
+
+// cdsTermsSize is the number of field elements used to encode CDS terms:
+// the reference entity, the tenor index, the notional and the standard coupon
+const cdsTermsSize = 4
+
+// cdsTenors are the standard CDS tenors, in years. The tenor of a CDS is encoded as its index.
+var cdsTenors = [...]int{1, 2, 3, 5, 7, 10}
+
+const (
+	// spreads are in hundredths of a basis point, upfront points in hundredths of a point
+	cdsQuoteDecimals = 2
+	// risky durations are in 10^-4 years
+	durationDecimals = 4
+	// quoted spreads and upfront points are smaller than 2^32 in absolute value, so the equivalent spreads don't overflow
+	maxCDSQuote = 1<<32 - 1
+	// upfront points are signed: they are encoded with this offset, so -maxCDSQuote..maxCDSQuote is 1..2^33-1
+	cdsUpfrontOffset = maxCDSQuote + 1
+)
+
+var (
+	errReferenceEntity = errors.New("reference entity does not fit in a field element")
+	errCDSTenor        = errors.New("not a standard CDS tenor")
+	errCDSQuote        = errors.New("CDS running spread must be positive, and upfront points smaller than 2^32 / 100, with at most 2 decimals")
+	errDuration        = errors.New("risky duration must be positive with at most 4 decimals")
+)
+
+// CDS holds the terms of a credit default swap the initiator buys protection on
+type CDS struct {
+	ReferenceEntity string
+	Tenor           int    // years, one of cdsTenors
+	Notional        string // integer
+	Coupon          int    // standard running coupon in basis points: 100 or 500
+}
+
+// CDSQuote is a dealer quote: a running spread in basis points and upfront points paid by the protection buyer.
+// Upfront points are negative when the buyer receives them, for a standard coupon above the spread of the name.
+type CDSQuote struct {
+	Running decimal.Decimal
+	Upfront decimal.Decimal
+}
+
+// CDSConversionTable holds the risky duration (in years) for each of cdsTenors.
+// It converts upfront points to an equivalent running spread: upfront * 100 / duration basis points.
+type CDSConversionTable [len(cdsTenors)]decimal.Decimal
+
+// Fields encodes the CDS terms as field elements so they can be used inside a circuit
+func (cds *CDS) Fields() ([cdsTermsSize]*big.Int, error) {
+	var fields [cdsTermsSize]*big.Int
+
+	if len(cds.ReferenceEntity) > textChunkSize {
+		return fields, errReferenceEntity
+	}
+	tenor := -1
+	for i, t := range cdsTenors {
+		if t == cds.Tenor {
+			tenor = i
+		}
+	}
+	if tenor < 0 {
+		return fields, errCDSTenor
+	}
+	notional, ok := new(big.Int).SetString(cds.Notional, 10)
+	if !ok || notional.Sign() < 0 {
+		return fields, errInvalidSize
+	}
+
+	fields[0] = new(big.Int).SetBytes([]byte(cds.ReferenceEntity))
+	fields[1] = big.NewInt(int64(tenor))
+	fields[2] = notional
+	fields[3] = big.NewInt(int64(cds.Coupon))
+	return fields, nil
+}
+
+// Hash returns the MiMC hash of the CDS terms. Dealers sign their quotes with it.
+func (cds *CDS) Hash() ([]byte, error) {
+	fields, err := cds.Fields()
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(fields[:]...), nil
+}
+
+// fields returns the running spread and upfront points as used in cdsCircuit, upfront points offset by cdsUpfrontOffset
+func (q *CDSQuote) fields() (running, upfront *big.Int, err error) {
+	r, u := q.Running.Shift(cdsQuoteDecimals), q.Upfront.Shift(cdsQuoteDecimals)
+	max := decimal.NewFromInt(maxCDSQuote)
+	for _, v := range []decimal.Decimal{r, u} {
+		if !v.Equal(v.Truncate(0)) || v.Abs().GreaterThan(max) {
+			return nil, nil, errCDSQuote
+		}
+	}
+	if r.Sign() < 0 {
+		return nil, nil, errCDSQuote
+	}
+	return r.BigInt(), u.Add(decimal.NewFromInt(cdsUpfrontOffset)).BigInt(), nil
+}
+
+// Hash returns the message a dealer signs for a quote on the CDS terms hash, in the RFQ with that ID
+func (q *CDSQuote) Hash(termsHash []byte, rfqID *big.Int) ([]byte, error) {
+	running, upfront, err := q.fields()
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(new(big.Int).SetBytes(termsHash), rfqID, running, upfront), nil
+}
+
+// EquivalentSpread returns the running spread, in basis points, equivalent to the quote for a tenor
+func (table *CDSConversionTable) EquivalentSpread(quote CDSQuote, tenor int) (decimal.Decimal, error) {
+	for i, t := range cdsTenors {
+		if t == tenor {
+			return quote.Running.Add(quote.Upfront.Mul(decimal.NewFromInt(100)).Div(table[i])), nil
+		}
+	}
+	return decimal.Zero, errCDSTenor
+}
+
+// Fields returns the risky durations scaled by 10^durationDecimals
+func (table *CDSConversionTable) Fields() ([len(cdsTenors)]*big.Int, error) {
+	var fields [len(cdsTenors)]*big.Int
+	for i, duration := range table {
+		d := duration.Shift(durationDecimals)
+		if !d.Equal(d.Truncate(0)) || d.Sign() <= 0 {
+			return fields, errDuration
+		}
+		fields[i] = d.BigInt()
+	}
+	return fields, nil
+}
+
+// cdsCircuit is the RFQ circuit for credit default swaps, modelled on bondCircuit: the initiator buys
+// protection and accepts the quote with the smallest running spread once upfront points are converted
+// with the public conversion table. Spreads are compared multiplied by the risky duration:
+// equivalent * duration = running * duration + upfront * 100, in the units of the encoded values.
+// Upfront points can be negative, so they are offset by cdsUpfrontOffset: the comparison adds the same
+// offset to every quote, and an equivalent spread is positive if it is above the offset.
+type cdsCircuit struct {
+	Terms           frontend.Variable                 `gnark:",public"`  // hash of the CDS terms
+	RFQID           frontend.Variable                 `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	ConversionTable [len(cdsTenors)]frontend.Variable `gnark:",public"`  // risky duration per tenor
+	PublicKeyCpts   [3]PublicKey                      `gnark:",public"`  // counterparties asked for a quote
+	AcceptedRunning frontend.Variable                 `gnark:",public"`  // running spread of the accepted quote
+	AcceptedUpfront frontend.Variable                 `gnark:",public"`  // upfront points of the accepted quote, plus cdsUpfrontOffset
+	TermsAttributes [cdsTermsSize]frontend.Variable   `gnark:",private"` // as in CDS.Fields
+	RunningFromCpts [3]frontend.Variable              `gnark:",private"`
+	UpfrontFromCpts [3]frontend.Variable              `gnark:",private"` // plus cdsUpfrontOffset
+	QuoteSignedCpts [3]Signature                      `gnark:",private"` // Sign(Terms, RFQID, running, upfront)
+	Accepted        frontend.Variable                 `gnark:",private"` // index of the accepted counterparty
+}
+
+func (circuit *cdsCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	cs.AssertIsEqual(circuit.Terms, mimc.Hash(cs, circuit.TermsAttributes[:]...))
+	duration := selectByIndex(cs, curveID, circuit.TermsAttributes[1], circuit.ConversionTable[:])
+
+	// upfront points are converted to basis points of running spread
+	upfrontToSpread := new(big.Int).Exp(big.NewInt(10), big.NewInt(durationDecimals), nil)
+	upfrontToSpread.Mul(upfrontToSpread, big.NewInt(100))
+	zero := new(big.Int).Mul(upfrontToSpread, big.NewInt(cdsUpfrontOffset))
+
+	equivalents := make([]frontend.Variable, len(circuit.RunningFromCpts))
+	for i := 0; i < len(circuit.RunningFromCpts); i++ {
+		running, upfront := circuit.RunningFromCpts[i], circuit.UpfrontFromCpts[i]
+		cs.AssertIsLessOrEqual(running, maxCDSQuote)
+		cs.AssertIsLessOrEqual(upfront, 2*maxCDSQuote+1)
+
+		// the quote is valid only for those terms in this RFQ, and the cpt
+		circuit.PublicKeyCpts[i].Curve = params
+		quoteHash := mimc.Hash(cs, circuit.Terms, circuit.RFQID, running, upfront)
+		mustBeCanonical(cs, circuit.QuoteSignedCpts[i])
+		eddsa.Verify(cs, circuit.QuoteSignedCpts[i], quoteHash, circuit.PublicKeyCpts[i])
+
+		equivalents[i] = cs.Add(cs.Mul(running, duration), cs.Mul(upfront, upfrontToSpread))
+
+		// All quotes should be greater than zero, the offset upfront points of a zero quote
+		cs.AssertIsLessOrEqual(cs.Constant(new(big.Int).Add(zero, big.NewInt(1))), equivalents[i])
+	}
+
+	// the accepted quote has the smallest equivalent spread
+	accepted := selectByIndex(cs, curveID, circuit.Accepted, equivalents)
+	for i := 0; i < len(equivalents); i++ {
+		cs.AssertIsLessOrEqual(accepted, equivalents[i])
+	}
+	cs.AssertIsEqual(circuit.AcceptedRunning, selectByIndex(cs, curveID, circuit.Accepted, circuit.RunningFromCpts[:]))
+	cs.AssertIsEqual(circuit.AcceptedUpfront, selectByIndex(cs, curveID, circuit.Accepted, circuit.UpfrontFromCpts[:]))
+
+	return nil
+}
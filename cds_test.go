@@ -0,0 +1,160 @@
+package financial
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestCDSCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit cdsCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	cds := &CDS{ReferenceEntity: "Enbridge Inc", Tenor: 5, Notional: "10000000", Coupon: 100}
+	table := CDSConversionTable{
+		decimal.RequireFromString("0.98"),
+		decimal.RequireFromString("1.93"),
+		decimal.RequireFromString("2.85"),
+		decimal.RequireFromString("4.55"),
+		decimal.RequireFromString("6.1"),
+		decimal.RequireFromString("8.2"),
+	}
+	quotes := []CDSQuote{
+		{Running: decimal.NewFromInt(100), Upfront: decimal.RequireFromString("1.5")},
+		{Running: decimal.NewFromInt(120), Upfront: decimal.RequireFromString("0.5")},
+		{Running: decimal.NewFromInt(140), Upfront: decimal.Zero},
+	}
+
+	// 100 + 150 / 4.55 > 120 + 50 / 4.55: the second quote is the best one
+	best, bestSpread := -1, decimal.Zero
+	for i, quote := range quotes {
+		spread, err := table.EquivalentSpread(quote, cds.Tenor)
+		assert.NoError(err)
+		if best < 0 || spread.LessThan(bestSpread) {
+			best, bestSpread = i, spread
+		}
+	}
+	assert.Equal(1, best)
+
+	termsHash, err := cds.Hash()
+	assert.NoError(err)
+	rfqID := big.NewInt(1)
+	terms, err := cds.Fields()
+	assert.NoError(err)
+	durations, err := table.Fields()
+	assert.NoError(err)
+	hFunc := hash.MIMC_BN254.New("seed")
+
+	// quoted returns the witness of the quotes on the terms, where the quote at index is accepted
+	quoted := func(quotes []CDSQuote, index int) *cdsCircuit {
+		var w cdsCircuit
+		w.Terms.Assign(termsHash)
+		w.RFQID.Assign(rfqID)
+		for i := range terms {
+			w.TermsAttributes[i].Assign(terms[i])
+		}
+		for i := range durations {
+			w.ConversionTable[i].Assign(durations[i])
+		}
+		for i, quote := range quotes {
+			privKey, err := signature.EDDSA_BN254.New(rand.New(rand.NewSource(int64(i + 1))))
+			assert.NoError(err)
+
+			running, upfront, err := quote.fields()
+			assert.NoError(err)
+			msg, err := quote.Hash(termsHash, rfqID)
+			assert.NoError(err)
+			signed, err := privKey.Sign(msg, hFunc)
+			assert.NoError(err)
+
+			assignPublicKey(&w.PublicKeyCpts[i], privKey.Public().Bytes())
+			w.RunningFromCpts[i].Assign(running)
+			w.UpfrontFromCpts[i].Assign(upfront)
+			assignSignature(&w.QuoteSignedCpts[i], signed)
+		}
+		running, upfront, err := quotes[index].fields()
+		assert.NoError(err)
+		w.Accepted.Assign(index)
+		w.AcceptedRunning.Assign(running)
+		w.AcceptedUpfront.Assign(upfront)
+		return &w
+	}
+	accept := func(index int) *cdsCircuit {
+		return quoted(quotes, index)
+	}
+	assert.SolvingSucceeded(r1cs, accept(1))
+
+	// the smallest running spread isn't the best quote once upfront points are converted
+	assert.SolvingFailed(r1cs, accept(0))
+	assert.SolvingFailed(r1cs, accept(2))
+
+	// with another conversion table the first quote is the best one
+	cheapUpfront := accept(0)
+	cheapUpfront.ConversionTable[3] = frontend.Variable{}
+	cheapUpfront.ConversionTable[3].Assign(500000)
+	assert.SolvingSucceeded(r1cs, cheapUpfront)
+
+	// the public accepted quote must be the selected one
+	wrongQuote := accept(1)
+	wrongQuote.AcceptedUpfront = frontend.Variable{}
+	wrongQuote.AcceptedUpfront.Assign(0)
+	assert.SolvingFailed(r1cs, wrongQuote)
+
+	// quotes are signed on the terms: another tenor fails
+	otherTenor := accept(1)
+	otherTenor.TermsAttributes[1] = frontend.Variable{}
+	otherTenor.TermsAttributes[1].Assign(4)
+	otherTenor.Terms = frontend.Variable{}
+	otherTerms := terms
+	otherTerms[1] = big.NewInt(4)
+	otherTenor.Terms.Assign(hashFields(otherTerms[:]...))
+	assert.SolvingFailed(r1cs, otherTenor)
+
+	// and for the RFQ: quotes signed for another one fail
+	otherRFQ := accept(1)
+	otherRFQ.RFQID = frontend.Variable{}
+	otherRFQ.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, otherRFQ)
+
+	// on a 100bp coupon the buyer receives upfront points for a name trading tighter:
+	// 100 - 50 / 4.55 > 100 - 60 / 4.55, the third quote is the best one
+	tight := []CDSQuote{
+		{Running: decimal.NewFromInt(100), Upfront: decimal.RequireFromString("-0.5")},
+		{Running: decimal.NewFromInt(100), Upfront: decimal.RequireFromString("0.25")},
+		{Running: decimal.NewFromInt(100), Upfront: decimal.RequireFromString("-0.6")},
+	}
+	spread, err := table.EquivalentSpread(tight[2], cds.Tenor)
+	assert.NoError(err)
+	assert.True(spread.LessThan(decimal.NewFromInt(100)))
+	assert.SolvingSucceeded(r1cs, quoted(tight, 2))
+	assert.SolvingFailed(r1cs, quoted(tight, 0))
+
+	// but the equivalent spread stays positive
+	tight[1].Upfront = decimal.RequireFromString("-5")
+	assert.SolvingFailed(r1cs, quoted(tight, 1))
+	assert.SolvingFailed(r1cs, quoted(tight, 2))
+
+	for _, quote := range []CDSQuote{
+		{Running: decimal.NewFromInt(-1), Upfront: decimal.Zero},
+		{Running: decimal.NewFromInt(100), Upfront: decimal.RequireFromString("-0.001")},
+		{Running: decimal.NewFromInt(100), Upfront: decimal.NewFromInt(-maxCDSQuote)},
+	} {
+		_, _, err = quote.fields()
+		assert.Error(err, quote)
+	}
+
+	_, err = (&CDS{ReferenceEntity: "Enbridge Inc", Tenor: 4, Notional: "10000000", Coupon: 100}).Fields()
+	assert.Error(err)
+}
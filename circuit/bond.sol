@@ -155,7 +155,7 @@ contract Verifier {
         Pairing.G2Point beta2;
         Pairing.G2Point gamma2;
         Pairing.G2Point delta2;
-        Pairing.G1Point[13] IC;
+        Pairing.G1Point[54] IC;
     }
 
     struct Proof {
@@ -165,23 +165,64 @@ contract Verifier {
     }
 
     function verifyingKey() internal pure returns (VerifyingKey memory vk) {
-        vk.alfa1 = Pairing.G1Point(uint256(7861457819783165755907655065085216834560522205432649486855270260376402376690), uint256(20674988008115679118498254639030499012402835306603444152358866137383965599671));
-        vk.beta2 = Pairing.G2Point([uint256(2885106321571658514530213488347171682199715684548027277809567275739161241240), uint256(21878403933772911112416100744343154539551838511643237840137230658951016749448)], [uint256(19325382700868445072752747062250288841541285803913275311317975568900625158048), uint256(3387273068439238347048400388719555959754899473482436471385953526232579731405)]);
-        vk.gamma2 = Pairing.G2Point([uint256(21531170436768095912953563931458668542020926253757359441850042582405408432984), uint256(1588676248761259975382901568047532330357830319763186913747021378104910550084)], [uint256(13109612067336967402763842217322792063978743691059644077837993978728192769803), uint256(13645542374284919934866046332065480148935745134982162800890628514745821528838)]);
-        vk.delta2 = Pairing.G2Point([uint256(21834087906559473080491140105048863535491675089124137177484653336842385880195), uint256(7862543341552791667595200097847237457155132372844109662070055666743430669071)], [uint256(15429421171369878905296667296659542876322115874867585842193432272778991701969), uint256(18428152555156282819870708221710389973720398849271837155702945239390512021522)]);   
-        vk.IC[0] = Pairing.G1Point(uint256(18899520182050158967888069795485137422346862893312262020469710738582877817178), uint256(15817909895044064689143181608158478241486990923764337630408111877283176533516));   
-        vk.IC[1] = Pairing.G1Point(uint256(1693851977746321254672115214736401738693357952645269400487177119923959434084), uint256(1009388183183617396039711135097514866897935439172334529726679383505552881982));   
-        vk.IC[2] = Pairing.G1Point(uint256(18300974015880879913549854663965447167598563334058312230405686230012278056954), uint256(1534587861027527631850510372952841392507100617616162112685971655741112944142));   
-        vk.IC[3] = Pairing.G1Point(uint256(9463697636072721442128830094551758043505415337236934896722659847629229336245), uint256(7995947712315759111613052891022008213044028952989578594555874259081153553789));   
-        vk.IC[4] = Pairing.G1Point(uint256(1571418015988769243420803663216974326426899747211251175397284797774607361653), uint256(3601433518126464810924419930401294452883948003830184744193847004378831500313));   
-        vk.IC[5] = Pairing.G1Point(uint256(886823220384534012450722138466097735539353757008231223675845638152514823069), uint256(6272619607426823222981743275448804138253377842853523301500236851156227122979));   
-        vk.IC[6] = Pairing.G1Point(uint256(3395398287902870544561101185725251825352119461956761950689850476787686749132), uint256(3326727870581640985971195717209551199636424056163644885721306482541484137701));   
-        vk.IC[7] = Pairing.G1Point(uint256(12201145749545918589080380595660551583217638901860366337790823755263586995847), uint256(5418201669549142078962702185826410757806433980030253401822208491023604252206));   
-        vk.IC[8] = Pairing.G1Point(uint256(6633230215531318201907654913118239373412883844124933726208895100063462694038), uint256(20127327642922206362451993065970260011211273340980372771609627792590147478118));   
-        vk.IC[9] = Pairing.G1Point(uint256(7045665241117250971198619580963164188720976259848808775701371760670067906219), uint256(17752909107290280091629789291101998006587573002116671118263354475809460730518));   
-        vk.IC[10] = Pairing.G1Point(uint256(1052922285400538534131393711830757326942620835072469543882150843106313806699), uint256(21411095605616861016864488570443946869177344179167545390612115957247416460691));   
-        vk.IC[11] = Pairing.G1Point(uint256(4123274154319222559219329329553626940429704438500412485585265163439293781372), uint256(20456675470381975413000962857035582996645445583870372966321765647344298072258));   
-        vk.IC[12] = Pairing.G1Point(uint256(14219193232840512705464186378348062021697277351225910788562791646362945789907), uint256(5564405655179070979240821540788412535222460609024238793351920784728471109498));
+        vk.alfa1 = Pairing.G1Point(uint256(6216061285261505261064700104924656423278341311786382114188104902194023428461), uint256(9638116115574018081364677039232321058771997738373249452400978439728499786663));
+        vk.beta2 = Pairing.G2Point([uint256(16414984049941640429823174944211413808008665789239630401712570881211635131737), uint256(9314264575502136232749791473039441262885522272009880436561150406300467486106)], [uint256(8482401348284487592987665992298562666099432394290004524767794486317910521504), uint256(11772167288093764789704641176800065572260081926720479068938707380453826634161)]);
+        vk.gamma2 = Pairing.G2Point([uint256(4317166945129037770624854054993570198774931200284744522711243788458477112620), uint256(9537230210684002978930085951295365842327905387314374962528990000647933287464)], [uint256(18078067263947103107548961675238286254533083805767214605618956111447251307458), uint256(18193861791093443443112238626637696393999277720445181123328456849565801581714)]);
+        vk.delta2 = Pairing.G2Point([uint256(9547680662276450834632783258139307643479029873858513736911392809295993695770), uint256(12076130636584204709114013148304333113530412662992144328328423830182065556359)], [uint256(14459788230384030782501297562238748524187560764160201993215220797451480249425), uint256(16240960530419659923814046275643858711176287902269385285954248627348583587734)]);   
+        vk.IC[0] = Pairing.G1Point(uint256(7361800754483607786490694374015163060282044150582242532997883380996804021626), uint256(21146685070038941851021230136589852091021819999439913043930012168072093326465));   
+        vk.IC[1] = Pairing.G1Point(uint256(7299890428307217572203860354292064414490797339085159482980356104345285620350), uint256(6638557851332759966447238349451312816300672843125027800659815877237750259667));   
+        vk.IC[2] = Pairing.G1Point(uint256(14531297190371588152789875731079666232734613139284233230221652048706337426834), uint256(7810979488144681374946767058365066974335728189652314309594377767919461872169));   
+        vk.IC[3] = Pairing.G1Point(uint256(2048593031104258236757165897625480487250504920773648359830353357399349691290), uint256(1563678772781307804455936605894396423698661929309946652637140726140621800176));   
+        vk.IC[4] = Pairing.G1Point(uint256(1772074186049578840690669824406405367152401615307819521291714720512437063194), uint256(11388883798945241979084006505091731230930804415041793816630497128540122321439));   
+        vk.IC[5] = Pairing.G1Point(uint256(163664286258883927200713488720724628431017114589588525491023592555999922371), uint256(7512855208165690121240660600022436340435091011205783900252929062838466798288));   
+        vk.IC[6] = Pairing.G1Point(uint256(10186040301365979945719569434956800933613706961084759293898845153654373318899), uint256(1472356975416936889550632562747052011809002501931135689293227689655733931577));   
+        vk.IC[7] = Pairing.G1Point(uint256(13535169123031597860296632740427090729005029234207335806931450452507641181712), uint256(18763671573869135352408940287450232559943533944023030349514025818871573321407));   
+        vk.IC[8] = Pairing.G1Point(uint256(10434360480292102566775927291138265542513190986754051063243135337432153060261), uint256(18180608037778262803581946692344100093008673116226499048319262942595166157363));   
+        vk.IC[9] = Pairing.G1Point(uint256(6686770699049438550046156379331375321130370986216318379650378676305462632248), uint256(10188693856717172764426782380042527796148058499266025337506732643131505304118));   
+        vk.IC[10] = Pairing.G1Point(uint256(1374557685565928124486528391305538019265430241038103447540641147018607722322), uint256(12699926531076602245468684121131961539292213981657095196533268671050624606540));   
+        vk.IC[11] = Pairing.G1Point(uint256(18414248685795835123950885731176587522482810787250707500790977407481906453193), uint256(8535673362813008914943531519043894768086454060740009807186665883183023448743));   
+        vk.IC[12] = Pairing.G1Point(uint256(13113327399544177195639237940689043911349325758066010676206626065163359364304), uint256(8427196619366412079343082711737951164578873316904671993959597474513187805857));   
+        vk.IC[13] = Pairing.G1Point(uint256(14790872419985406897530383824733592082603790603119832406307209070058998646634), uint256(13002235482952839274601564057262241549056700162985541231536555851808292945187));   
+        vk.IC[14] = Pairing.G1Point(uint256(6536482687552463338841902964413969750635652445401052967465413701560581761199), uint256(18384817173293652280236055339789110781331174618407322775292853997439010484335));   
+        vk.IC[15] = Pairing.G1Point(uint256(3131671779855423234854586254350338684614558361782822610749846928204266431062), uint256(19801089307589186580424540927869258166073155619221693790138660287190795899128));   
+        vk.IC[16] = Pairing.G1Point(uint256(14832410076012535244734322067010826068103685255262098231372820684822864218310), uint256(5140294020095184190327151001907010751680817353161913680419639854995556736102));   
+        vk.IC[17] = Pairing.G1Point(uint256(21411447864707715562108531875996457126529240145690590382333715907805820357631), uint256(17980250464901329401163729558658883902132675808436017021995188606648992583529));   
+        vk.IC[18] = Pairing.G1Point(uint256(2568564625111669179920913786534114638226611395193646674380371244284529671221), uint256(4008237520957241858855445486352429829729386096886156163888606422885250458607));   
+        vk.IC[19] = Pairing.G1Point(uint256(1422706420404703822777897298120101287114686316333421122451943279591453738397), uint256(4333310388678941354630411824305727999506838231827642665580322385175676600420));   
+        vk.IC[20] = Pairing.G1Point(uint256(17267078149392401101182046569521613800538544269149848349884404363543324882239), uint256(9418343539489352029507090879641266748755173206332309374668408116139085386942));   
+        vk.IC[21] = Pairing.G1Point(uint256(4747889524038342926775978623175563805902083744445780558714125354551123171550), uint256(16869177225432296332427050880840596847145814833071675808169319235303924945354));   
+        vk.IC[22] = Pairing.G1Point(uint256(17764939794411830099038081264855825241083447249906597328729626292733200923279), uint256(10917228954021864680644207930151325892741865121991684287152014833191920753016));   
+        vk.IC[23] = Pairing.G1Point(uint256(37472557713560391472261811228204759423009556313671422179741589039663307424), uint256(8850346264879496261768591530491869945769610268287509325064121464359318047449));   
+        vk.IC[24] = Pairing.G1Point(uint256(4879665023241479647747886310730442518768877008044557224918425138037662365429), uint256(9463127606174601770890223226897392674758664177824792301110041336784940912891));   
+        vk.IC[25] = Pairing.G1Point(uint256(17074211962915871092850558627027480990021849247890680497064836239948929571278), uint256(7922039670088022303946686060367620117085306755267444998932545470490186542896));   
+        vk.IC[26] = Pairing.G1Point(uint256(16150575395060237500227111867694235912478808395104060682543262417848668474917), uint256(16449688096100930724941824120052634158996884741655155326686737088430433678558));   
+        vk.IC[27] = Pairing.G1Point(uint256(20332638423925003078794515695839162732688484531284613782032386817553497291474), uint256(18521967725390608097350583053788345332661378899440414572802626644441247187829));   
+        vk.IC[28] = Pairing.G1Point(uint256(7592010158458331955005649329118110313337412287035375281976726311718664791631), uint256(19120968469373676907028579508669540138067617535974938471594201288722347927431));   
+        vk.IC[29] = Pairing.G1Point(uint256(11392999643546113103631498399598752252374920316099656387708435624318867327872), uint256(8229122138054462541162666362712789296136296214357444456591763003150421055242));   
+        vk.IC[30] = Pairing.G1Point(uint256(3585080593299388291498863650943502464072188556046277012135609510202078924869), uint256(5423642315173730291611483438515510166045829655617159139936752980649171172319));   
+        vk.IC[31] = Pairing.G1Point(uint256(11898654423480641097109613064454982746212257142790341520889331944221696961175), uint256(10265727267449352229202525648858015875678902574433070136520488093995879536541));   
+        vk.IC[32] = Pairing.G1Point(uint256(9095656442416688463914381717435167789216218870988996421814957382384607773332), uint256(21826415218197982656102609145866559934526747906806203745605541100125540082370));   
+        vk.IC[33] = Pairing.G1Point(uint256(10343139065383454591724826938110288368565710066905362678275215853664289349698), uint256(11437278538173989819569650508080295125168074438334992618667849730652920713143));   
+        vk.IC[34] = Pairing.G1Point(uint256(3776441197095816671000411208262152573596353476687335857209588357179977850563), uint256(1181960924589497126144492400557223411820451064685223207580523045761418288584));   
+        vk.IC[35] = Pairing.G1Point(uint256(15346830560009176572892873297676300619117051852485146214292000621485511318379), uint256(20987675409978712562384630113840747214316660299711383340795609492751149637529));   
+        vk.IC[36] = Pairing.G1Point(uint256(13058805834155771115505718090397572625229526442226282573752786443521813753810), uint256(1206237279645622613830363079135630875482767501369689099710450321876446295102));   
+        vk.IC[37] = Pairing.G1Point(uint256(14148370747937152458405928753899870108279002522772952256923224993770885942178), uint256(1561633738112767638715574173924508624436959458309060277233971817392597033371));   
+        vk.IC[38] = Pairing.G1Point(uint256(17072194101878852974656616097556387793156174547878812277030747465614786267762), uint256(7698570433093376739344467048011918853478785575482348370250923279082846239672));   
+        vk.IC[39] = Pairing.G1Point(uint256(16765412756219782704916454443242235072387169147854317856249653163018523089150), uint256(4757505951136608301823687400201116242987796404510813063726996028109078119022));   
+        vk.IC[40] = Pairing.G1Point(uint256(13273770696704276907641700454244765997758484735318999358111301488890162147150), uint256(2723890550640393750034281450111752853875646355865597010701292776787033535470));   
+        vk.IC[41] = Pairing.G1Point(uint256(9289714714457824105465894074745195472591384374197343396819607016751426917003), uint256(11689105352173879310747720286132878924686421404379624414469043672793733809843));   
+        vk.IC[42] = Pairing.G1Point(uint256(7316213922278188476636196191969661498772581528209467859064455421987702461120), uint256(3515425096416913653844138386002925840100103198142220691917363472729892683685));   
+        vk.IC[43] = Pairing.G1Point(uint256(834180774528539520401223958304800300973745774290171697442087560205656659146), uint256(13366135466370737670711405434249979691032762051461762903864282491019083881955));   
+        vk.IC[44] = Pairing.G1Point(uint256(11322867597569648606611720938525874706234738574009393782453660445672133695341), uint256(10134533185434319386512999056216680795070443022193810203767098051341414892597));   
+        vk.IC[45] = Pairing.G1Point(uint256(16348822434472585464286678191756786364185316024213548936749022710323734536383), uint256(12653611344952140845763027065163043391201536455888396907784006531930461611783));   
+        vk.IC[46] = Pairing.G1Point(uint256(5801690678111171873149030572142280243202743673973032041238942238381428924673), uint256(3830816989078945808796246688112857042792757457110945454786119103241810081584));   
+        vk.IC[47] = Pairing.G1Point(uint256(20969933878475344435257871153796676362212688991437900513250740181548476431853), uint256(13799680210666242152413974465597193455868820394272265389731420673033432550844));   
+        vk.IC[48] = Pairing.G1Point(uint256(3591271699773941960317641851441372093102588053590700419898057377921825254247), uint256(11331508149619333044839733759448001508674673604968869141195401307621582835612));   
+        vk.IC[49] = Pairing.G1Point(uint256(6383429979829627354817223269906360323722219037342571006726901977330073013630), uint256(4664617317405022080015709792825538648880108913808287945236993638492290629401));   
+        vk.IC[50] = Pairing.G1Point(uint256(15399783780704570917436561737586977648919685493098602640744290911206496294850), uint256(14066329166955877112246887506728522279887301963215049865108351575678368860009));   
+        vk.IC[51] = Pairing.G1Point(uint256(2908254689082440104599153834363803124327111531167804582925278749860868118917), uint256(19694688570863350374111383516170485505453884964804395589188688884837691005983));   
+        vk.IC[52] = Pairing.G1Point(uint256(10838847727273694065853189638160329232109416008866014857827715402052415783955), uint256(21860673915637009424937000165659107251731129702398408102692404728218588542191));   
+        vk.IC[53] = Pairing.G1Point(uint256(16352643257143116311185296052137137573808217330320516088216203196297911037466), uint256(18757949012415826819982751324766955060902692618395471204163953716044870150943));
     }
     
     /*
@@ -192,7 +233,7 @@ contract Verifier {
         uint256[2] memory a,
         uint256[2][2] memory b,
         uint256[2] memory c,
-        uint256[12] memory input
+        uint256[53] memory input
     ) public view returns (bool r) {
 
         Proof memory proof;
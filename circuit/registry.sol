@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: AML
+pragma solidity ^0.8.0;
+
+import "./bond.sol";
+
+// NullifierRegistry records the nullifiers of the accepted proofs, so no signed quote is used twice
+contract NullifierRegistry {
+    mapping(uint256 => bool) public nullified;
+
+    event Nullified(uint256 nullifier);
+
+    function _nullify(uint256[] memory nullifiers) internal {
+        for (uint256 i = 0; i < nullifiers.length; i++) {
+            require(!nullified[nullifiers[i]], "nullifier already used");
+            nullified[nullifiers[i]] = true;
+            emit Nullified(nullifiers[i]);
+        }
+    }
+}
+
+// RFQRegistry accepts a valid proof only if none of its quotes was used in an accepted proof before
+contract RFQRegistry is Verifier, NullifierRegistry {
+    function submitProof(
+        uint256[2] memory a,
+        uint256[2][2] memory b,
+        uint256[2] memory c,
+        uint256[53] memory input
+    ) public returns (bool) {
+        require(verifyProof(a, b, c, input), "invalid proof");
+        uint256[] memory nullifiers = new uint256[](3);
+        nullifiers[0] = input[46];
+        nullifiers[1] = input[47];
+        nullifiers[2] = input[48];
+        _nullify(nullifiers);
+        return true;
+    }
+}
@@ -0,0 +1,282 @@
+// Command rfq prepares the inputs of a bond RFQ proof from reference data and dealer keys.
+//
+//	rfq bond -master bonds.json US46625HKC33
+//
+// prints the terms and the hash of a bond found by ISIN (or CUSIP) in a security master.
+//
+//	rfq open -master bonds.json -isin US46625HKC33 -dealers directory.json -id 7 -deadline 2021-11-19T16:00:00Z
+//
+// opens a sealed-bid RFQ for a bond of the security master and prints its public inputs.
+//
+//	rfq keygen -out dealer.key
+//	rfq pubkey -key dealer.key -name HSBC
+//
+// generate a dealer key, encrypted with the passphrase in $RFQ_PASSPHRASE, and export its public key as JSON.
+//
+//	rfq dealers -import dealers.json -out directory.json
+//
+// imports a directory of dealer public keys and prints the counterparty keys of the RFQ circuit.
+//
+//	rfq signer -key dealer.key -listen unix:/run/rfq/signer.sock -isins US46625HKC33 -max-notional 1000000
+//
+// runs the signer process of a dealer, which signs quotes allowed by its policy over HTTP.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	financial "bloconuts/v0"
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// passphraseEnv is the environment variable holding the passphrase of dealer keys
+const passphraseEnv = "RFQ_PASSPHRASE"
+
+var errNoPassphrase = errors.New("set the key passphrase in $" + passphraseEnv)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "bond":
+		err = bondCommand(os.Args[2:])
+	case "open":
+		err = openCommand(os.Args[2:])
+	case "keygen":
+		err = keygenCommand(os.Args[2:])
+	case "pubkey":
+		err = pubkeyCommand(os.Args[2:])
+	case "dealers":
+		err = dealersCommand(os.Args[2:])
+	case "signer":
+		err = signerCommand(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rfq:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: rfq bond -master file isin")
+	fmt.Fprintln(os.Stderr, "       rfq open -master file -isin isin -dealers file -id n -deadline time")
+	fmt.Fprintln(os.Stderr, "       rfq keygen -out file")
+	fmt.Fprintln(os.Stderr, "       rfq pubkey -key file [-name dealer]")
+	fmt.Fprintln(os.Stderr, "       rfq dealers -import file [-out file]")
+	fmt.Fprintln(os.Stderr, "       rfq signer -key file -listen addr [-isins list] [-max-notional size]")
+	os.Exit(2)
+}
+
+// bondCommand prints a bond of the security master
+func bondCommand(args []string) error {
+	flags := flag.NewFlagSet("bond", flag.ExitOnError)
+	master := flags.String("master", "bonds.json", "security master, .json or .csv")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+
+	bonds, err := financial.LoadSecurityMaster(*master)
+	if err != nil {
+		return err
+	}
+	bond, err := bonds.Lookup(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	hash, err := bond.Hash()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("isin      %s\n", bond.Isin)
+	fmt.Printf("ticker    %s\n", bond.Ticker)
+	fmt.Printf("type      %v\n", bond.Type)
+	fmt.Printf("size      %s\n", bond.Size)
+	fmt.Printf("coupon    %v %v, %d per year\n", bond.Coupon.Rate, bond.Coupon.DayCount, bond.Coupon.Frequency)
+	fmt.Printf("maturity  %s\n", bond.Coupon.Maturity.Format("2006-01-02"))
+	fmt.Printf("hash      %s\n", hex.EncodeToString(hash))
+	return nil
+}
+
+// openCommand opens a sealed-bid RFQ for a bond of the security master, sent to the dealers of a directory
+func openCommand(args []string) error {
+	flags := flag.NewFlagSet("open", flag.ExitOnError)
+	master := flags.String("master", "bonds.json", "security master, .json or .csv")
+	isin := flags.String("isin", "", "ISIN or CUSIP of the bond")
+	dealers := flags.String("dealers", "directory.json", "dealer directory written by rfq dealers")
+	id := flags.String("id", "", "RFQ ID, unique to the RFQ")
+	deadline := flags.String("deadline", "", "end of the commitment phase, RFC 3339")
+	flags.Parse(args)
+	if *isin == "" || *id == "" || *deadline == "" || flags.NArg() != 0 {
+		usage()
+	}
+
+	rfqID, ok := new(big.Int).SetString(*id, 10)
+	if !ok || rfqID.Sign() < 0 {
+		return fmt.Errorf("invalid RFQ ID %q", *id)
+	}
+	end, err := time.Parse(time.RFC3339, *deadline)
+	if err != nil {
+		return err
+	}
+	bonds, err := financial.LoadSecurityMaster(*master)
+	if err != nil {
+		return err
+	}
+	directory, err := keystore.LoadDirectory(*dealers)
+	if err != nil {
+		return err
+	}
+	keys := make([]signature.PublicKey, len(directory.Dealers))
+	for i, dealer := range directory.Dealers {
+		keys[i] = dealer.PublicKey
+	}
+
+	rfq, err := financial.OpenSealedBidRFQ(bonds, rfqID, *isin, end, keys)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rfq       %s\n", rfq.ID)
+	fmt.Printf("bond      %s\n", hex.EncodeToString(rfq.Bond))
+	fmt.Printf("deadline  %s\n", rfq.Deadline.Format(time.RFC3339))
+	for _, dealer := range directory.Dealers {
+		fmt.Printf("dealer    %s %s\n", dealer.Name, keystore.PublicKeyHex(dealer.PublicKey))
+	}
+	return nil
+}
+
+func passphrase() ([]byte, error) {
+	p := os.Getenv(passphraseEnv)
+	if p == "" {
+		return nil, errNoPassphrase
+	}
+	return []byte(p), nil
+}
+
+// keygenCommand generates a dealer key and saves it encrypted
+func keygenCommand(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := flags.String("out", "dealer.key", "encrypted key file")
+	flags.Parse(args)
+
+	p, err := passphrase()
+	if err != nil {
+		return err
+	}
+	key, err := keystore.Generate()
+	if err != nil {
+		return err
+	}
+	if err := keystore.Save(*out, key, p); err != nil {
+		return err
+	}
+	fmt.Println(keystore.PublicKeyHex(key.Public()))
+	return nil
+}
+
+// pubkeyCommand prints the public key of a dealer key as a directory entry
+func pubkeyCommand(args []string) error {
+	flags := flag.NewFlagSet("pubkey", flag.ExitOnError)
+	path := flags.String("key", "dealer.key", "encrypted key file")
+	name := flags.String("name", "", "dealer name")
+	flags.Parse(args)
+
+	p, err := passphrase()
+	if err != nil {
+		return err
+	}
+	key, err := keystore.Load(*path, p)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(keystore.Dealer{Name: *name, PublicKey: key.Public()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(buf))
+	return nil
+}
+
+// dealersCommand imports a dealer directory and prints the public keys as circuit inputs
+func dealersCommand(args []string) error {
+	flags := flag.NewFlagSet("dealers", flag.ExitOnError)
+	in := flags.String("import", "dealers.json", "dealer directory to import")
+	out := flags.String("out", "", "write the checked directory to this file")
+	flags.Parse(args)
+
+	directory, err := keystore.LoadDirectory(*in)
+	if err != nil {
+		return err
+	}
+	for i, dealer := range directory.Dealers {
+		pk, ok := dealer.PublicKey.(*eddsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s: not a bn254 key", dealer.Name)
+		}
+		fmt.Printf("PublicKeyCpts[%d] %s\n  A.X %s\n  A.Y %s\n", i, dealer.Name, pk.A.X.String(), pk.A.Y.String())
+	}
+	if *out != "" {
+		return directory.Save(*out)
+	}
+	return nil
+}
+
+// signerCommand serves quote signatures with a dealer key on a TCP address or a unix socket
+func signerCommand(args []string) error {
+	flags := flag.NewFlagSet("signer", flag.ExitOnError)
+	path := flags.String("key", "dealer.key", "encrypted key file")
+	listen := flags.String("listen", "unix:signer.sock", "host:port or unix:path")
+	isins := flags.String("isins", "", "comma separated ISINs the signer quotes, any if empty")
+	maxNotional := flags.String("max-notional", "", "largest bond size the signer quotes, any if empty")
+	flags.Parse(args)
+
+	policy := &financial.SigningPolicy{}
+	if *isins != "" {
+		policy.AllowedIsins = strings.Split(*isins, ",")
+	}
+	if *maxNotional != "" {
+		n, ok := new(big.Int).SetString(*maxNotional, 10)
+		if !ok {
+			return fmt.Errorf("invalid max notional %q", *maxNotional)
+		}
+		policy.MaxNotional = n
+	}
+
+	p, err := passphrase()
+	if err != nil {
+		return err
+	}
+	key, err := keystore.Load(*path, p)
+	if err != nil {
+		return err
+	}
+
+	network, address := "tcp", *listen
+	if strings.HasPrefix(address, "unix:") {
+		network, address = "unix", strings.TrimPrefix(address, "unix:")
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "signing for", keystore.PublicKeyHex(key.Public()), "on", *listen)
+	return http.Serve(listener, &financial.QuoteSignerServer{Key: key, Policy: policy})
+}
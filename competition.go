@@ -0,0 +1,33 @@
+package financial
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// mustBeDistinctKeys asserts no two dealers share a public key, so every quote is from another competitor
+func mustBeDistinctKeys(cs *frontend.ConstraintSystem, curveID ecc.ID, keys [3]PublicKey) {
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			cs.AssertIsEqual(isSameKey(cs, curveID, keys[i], keys[j]), 0)
+		}
+	}
+}
+
+// isSameKey returns 1 if a and b are the same point, 0 otherwise
+func isSameKey(cs *frontend.ConstraintSystem, curveID ecc.ID, a, b PublicKey) frontend.Variable {
+	// (x, y) and (x, -y) or (-x, y) are different keys
+	sameX := cs.IsZero(cs.Sub(a.A.X, b.A.X), curveID)
+	sameY := cs.IsZero(cs.Sub(a.A.Y, b.A.Y), curveID)
+	return cs.And(sameX, sameY)
+}
+
+// mustCompete asserts at least minQuotes dealers quoted, the best-execution rule of the initiator
+func mustCompete(cs *frontend.ConstraintSystem, quoteCount, minQuotes frontend.Variable) {
+	cs.AssertIsLessOrEqual(minQuotes, quoteCount)
+}
+
+// mustBeat asserts the quote better is strictly smaller than worse
+func mustBeat(cs *frontend.ConstraintSystem, better, worse frontend.Variable) {
+	cs.AssertIsLessOrEqual(cs.Add(better, 1), worse)
+}
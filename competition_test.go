@@ -0,0 +1,84 @@
+package financial
+
+import (
+	"math/big"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+type competitionCircuit struct {
+	PublicKeys [3]PublicKey         `gnark:",public"`
+	MinQuotes  frontend.Variable    `gnark:",public"`
+	Accepted   frontend.Variable    `gnark:",public"`
+	Quotes     [3]frontend.Variable `gnark:",private"`
+}
+
+func (circuit *competitionCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	mustBeDistinctKeys(cs, curveID, circuit.PublicKeys)
+	count := mustBeBestQuote(cs, curveID, circuit.Accepted, circuit.Quotes)
+	mustCompete(cs, count, circuit.MinQuotes)
+	return nil
+}
+
+func TestCompetition(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit competitionCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	var keys [3]signature.PublicKey
+	for i := range keys {
+		key, err := keystore.Generate()
+		assert.NoError(err)
+		keys[i] = key.Public()
+	}
+	low, high := big.NewInt(50946500), big.NewInt(52011500)
+
+	var witness competitionCircuit
+	for i := range keys {
+		assignPublicKey(&witness.PublicKeys[i], keys[i].Bytes())
+	}
+	witness.Accepted.Assign(low)
+	for i, quote := range []*big.Int{high, PassQuote, low} {
+		witness.Quotes[i].Assign(quote)
+	}
+
+	// two dealers quoted, one passed
+	for minQuotes := 0; minQuotes <= 3; minQuotes++ {
+		good := witness
+		good.MinQuotes.Assign(minQuotes)
+		if minQuotes <= 2 {
+			assert.SolvingSucceeded(r1cs, &good)
+		} else {
+			assert.SolvingFailed(r1cs, &good)
+		}
+	}
+	witness.MinQuotes.Assign(2)
+
+	// the same key can't quote twice to make up the count
+	bad := witness
+	bad.PublicKeys[1] = PublicKey{}
+	assignPublicKey(&bad.PublicKeys[1], keys[0].Bytes())
+	assert.SolvingFailed(r1cs, &bad)
+
+	// but a key sharing a coordinate with another is a different key
+	ax, ay := parsePoint(ecc.BN254, keys[0].Bytes())
+	var negX fr.Element
+	negX.SetBytes(ax)
+	negX.Neg(&negX)
+	good := witness
+	good.PublicKeys[1] = PublicKey{}
+	good.PublicKeys[1].A.X.Assign(negX.ToBigIntRegular(new(big.Int)))
+	good.PublicKeys[1].A.Y.Assign(ay)
+	assert.SolvingSucceeded(r1cs, &good)
+}
@@ -0,0 +1,256 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+	"github.com/shopspring/decimal"
+)
+
+// QuoteConvention is how a quote is expressed. It is signed with the quote, so a yield or a spread
+// can't be read as a price.
+type QuoteConvention int
+
+const (
+	PriceConvention  QuoteConvention = iota // clean price * size in cents, the smallest is the best
+	YieldConvention                         // yield scaled by 10^rateDecimals, the largest is the best
+	SpreadConvention                        // spread to the benchmark yield scaled by 10^rateDecimals, the largest is the best
+)
+
+// yieldTableSize is the number of points of a PriceYieldTable
+const yieldTableSize = 5
+
+// prices of a PriceYieldTable are in hundredths of a point, as quoted (92.63)
+const priceDecimals = 2
+
+var (
+	errConvention      = errors.New("unknown quote convention")
+	errYieldTable      = errors.New("yields must increase and prices decrease along the table, with at most 4 and 2 decimals")
+	errYieldOutOfTable = errors.New("yield is outside the price/yield table")
+)
+
+// ConventionQuote is a dealer quote in a convention, both signed for the bond
+type ConventionQuote struct {
+	Convention QuoteConvention
+	Quote      *big.Int
+	Signature  []byte // Sign(MiMC(bond hash, RFQ ID, convention, quote))
+}
+
+// conventionQuoteHash is the message of a quote in a convention signed for a bond in a RFQ, checked in conventionCircuit
+func conventionQuoteHash(bondHash []byte, rfqID *big.Int, convention QuoteConvention, quote *big.Int) []byte {
+	return hashFields(new(big.Int).SetBytes(bondHash), rfqID, big.NewInt(int64(convention)), quote)
+}
+
+// SignConventionQuote signs a quote in a convention for the bond with that hash, in the RFQ with that ID
+func SignConventionQuote(key signature.Signer, bondHash []byte, rfqID *big.Int, convention QuoteConvention, quote *big.Int) (*ConventionQuote, error) {
+	if convention < PriceConvention || convention > SpreadConvention {
+		return nil, errConvention
+	}
+	if quote.Sign() <= 0 || quote.Cmp(maxQuote) >= 0 {
+		return nil, errMalformedQuote
+	}
+	sig, err := key.Sign(conventionQuoteHash(bondHash, rfqID, convention, quote), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	return &ConventionQuote{Convention: convention, Quote: new(big.Int).Set(quote), Signature: sig}, nil
+}
+
+// Verify checks the quote and its convention are signed by the dealer for the bond in the RFQ with that ID
+func (q *ConventionQuote) Verify(dealer signature.PublicKey, bondHash []byte, rfqID *big.Int) bool {
+	return verifySignature(dealer, q.Signature, conventionQuoteHash(bondHash, rfqID, q.Convention, q.Quote))
+}
+
+// Better reports whether quote a is strictly better than b for the initiator buying the bond:
+// a smaller price, or a larger yield or spread
+func (c QuoteConvention) Better(a, b *big.Int) bool {
+	if c == PriceConvention {
+		return a.Cmp(b) < 0
+	}
+	return a.Cmp(b) > 0
+}
+
+// YieldPoint is a point of a PriceYieldTable: the clean price of the bond at a yield, in percent
+type YieldPoint struct {
+	Yield decimal.Decimal
+	Price decimal.Decimal
+}
+
+// PriceYieldTable holds the clean price of a bond at increasing yields, for the pricing date.
+// Prices between two points are interpolated linearly: the drop from the point below is rounded down,
+// so the price is rounded up to priceDecimals.
+type PriceYieldTable [yieldTableSize]YieldPoint
+
+// Fields returns the yields scaled by 10^rateDecimals and the prices scaled by 10^priceDecimals
+func (table *PriceYieldTable) Fields() (yields, prices [yieldTableSize]*big.Int, err error) {
+	for i, point := range table {
+		y, p := point.Yield.Shift(rateDecimals), point.Price.Shift(priceDecimals)
+		if !y.Equal(y.Truncate(0)) || !p.Equal(p.Truncate(0)) || y.Sign() < 0 || p.Sign() <= 0 {
+			return yields, prices, errYieldTable
+		}
+		yields[i], prices[i] = y.BigInt(), p.BigInt()
+		if i > 0 && (yields[i].Cmp(yields[i-1]) <= 0 || prices[i].Cmp(prices[i-1]) > 0) {
+			return yields, prices, errYieldTable
+		}
+	}
+	return yields, prices, nil
+}
+
+// interpolate returns the price at a yield, scaled as in Fields, and the index of the point before it
+func (table *PriceYieldTable) interpolate(yield *big.Int) (*big.Int, int, error) {
+	yields, prices, err := table.Fields()
+	if err != nil {
+		return nil, 0, err
+	}
+	for k := 0; k+1 < yieldTableSize; k++ {
+		if yield.Cmp(yields[k]) < 0 || yield.Cmp(yields[k+1]) > 0 {
+			continue
+		}
+		// price = p_k - floor((p_k - p_k+1) * (yield - y_k) / (y_k+1 - y_k))
+		num := new(big.Int).Sub(prices[k], prices[k+1])
+		num.Mul(num, new(big.Int).Sub(yield, yields[k]))
+		num.Quo(num, new(big.Int).Sub(yields[k+1], yields[k]))
+		return num.Sub(prices[k], num), k, nil
+	}
+	return nil, 0, errYieldOutOfTable
+}
+
+// SpreadPrice returns the clean price of the bond quoted at a spread to the benchmark fixing
+func (table *PriceYieldTable) SpreadPrice(benchmark bondmath.Fixing, spread decimal.Decimal) (decimal.Decimal, error) {
+	yield := benchmark.Rate.Add(spread).Shift(rateDecimals)
+	if !yield.Equal(yield.Truncate(0)) {
+		return decimal.Zero, errYieldOutOfTable
+	}
+	price, _, err := table.interpolate(yield.BigInt())
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromBigInt(price, -priceDecimals), nil
+}
+
+// conventionCircuit is the RFQ variant where the quotes of all dealers are in the public Convention,
+// bound into each signature. The accepted quote is the smallest price, or the largest yield or spread.
+type conventionCircuit struct {
+	Bond                  frontend.Variable    `gnark:",public"`  // hash of the bond attributes
+	RFQID                 frontend.Variable    `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	PublicKeyCpts         [3]PublicKey         `gnark:",public"`  // counterparties asked for a quote
+	Convention            frontend.Variable    `gnark:",public"`  // PriceConvention, YieldConvention or SpreadConvention
+	AcceptedQuote         frontend.Variable    `gnark:",public"`  // in the convention
+	QuoteFromCpts         [3]frontend.Variable `gnark:",private"` // in the convention
+	ConventionQuoteSigned [3]Signature         `gnark:",private"` // Sign(Bond hash, RFQID, convention, quote)
+	Accepted              frontend.Variable    `gnark:",private"` // index of the accepted counterparty
+}
+
+func (circuit *conventionCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	convention := circuit.Convention
+	cs.AssertIsEqual(cs.Mul(convention, cs.Sub(convention, 1), cs.Sub(convention, 2)), 0)
+	isPrice := cs.IsZero(convention, curveID)
+
+	for i := range circuit.QuoteFromCpts {
+		cs.AssertIsEqual(cs.IsZero(circuit.QuoteFromCpts[i], curveID), 0)
+
+		// the quote is valid only in that convention, for that bond in this RFQ and the cpt
+		circuit.PublicKeyCpts[i].Curve = params
+		message := mimc.Hash(cs, circuit.Bond, circuit.RFQID, convention, circuit.QuoteFromCpts[i])
+		mustBeCanonical(cs, circuit.ConventionQuoteSigned[i])
+		eddsa.Verify(cs, circuit.ConventionQuoteSigned[i], message, circuit.PublicKeyCpts[i])
+	}
+
+	// the accepted quote is the best one in the direction of the convention
+	accepted := selectByIndex(cs, curveID, circuit.Accepted, circuit.QuoteFromCpts[:])
+	cs.AssertIsEqual(circuit.AcceptedQuote, accepted)
+	for i := range circuit.QuoteFromCpts {
+		cs.AssertIsLessOrEqual(cs.Select(isPrice, accepted, circuit.QuoteFromCpts[i]),
+			cs.Select(isPrice, circuit.QuoteFromCpts[i], accepted))
+	}
+
+	return nil
+}
+
+// spreadPriceCircuit converts the spread accepted in a conventionCircuit proof to a clean price: the yield
+// is the benchmark fixing signed by a rate publisher plus the spread, and the price is read from the public
+// price/yield table of the bond, interpolated as in PriceYieldTable. The spread is signed for the public
+// bond and RFQ in the spread convention by one of the counterparties, so it matches the conventionCircuit
+// proof with the same Bond, RFQID, PublicKeyCpts and AcceptedQuote.
+type spreadPriceCircuit struct {
+	Bond            frontend.Variable                 `gnark:",public"`  // hash of the bond attributes
+	RFQID           frontend.Variable                 `gnark:",public"`  // unique to the RFQ, signed with the spread
+	PublicKeyCpts   [3]PublicKey                      `gnark:",public"`  // counterparties asked for a quote
+	PublisherKey    PublicKey                         `gnark:",public"`  // public key of the rate publisher
+	Benchmark       frontend.Variable                 `gnark:",public"`  // name of the benchmark, as a fixing index
+	PricingDate     [3]frontend.Variable              `gnark:",public"`  // year, month, day
+	Spread          frontend.Variable                 `gnark:",public"`  // accepted spread, scaled by 10^rateDecimals
+	Yields          [yieldTableSize]frontend.Variable `gnark:",public"`  // scaled by 10^rateDecimals
+	Prices          [yieldTableSize]frontend.Variable `gnark:",public"`  // scaled by 10^priceDecimals
+	Price           frontend.Variable                 `gnark:",public"`  // clean price, scaled by 10^priceDecimals
+	BenchmarkYield  frontend.Variable                 `gnark:",private"` // fixing of the benchmark at the pricing date
+	BenchmarkSigned Signature                         `gnark:",private"` // Sign(fixing hash) by the rate publisher
+	SpreadSigned    Signature                         `gnark:",private"` // Sign(Bond hash, RFQID, SpreadConvention, spread)
+	Accepted        frontend.Variable                 `gnark:",private"` // index of the counterparty of the spread
+	Bracket         frontend.Variable                 `gnark:",private"` // index of the table point below the yield
+}
+
+func (circuit *spreadPriceCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	// the spread is quoted for that bond in this RFQ, in the spread convention, by the accepted counterparty
+	var xs, ys [3]frontend.Variable
+	for i := range circuit.PublicKeyCpts {
+		xs[i], ys[i] = circuit.PublicKeyCpts[i].A.X, circuit.PublicKeyCpts[i].A.Y
+	}
+	var dealer PublicKey
+	dealer.A.X = selectByIndex(cs, curveID, circuit.Accepted, xs[:])
+	dealer.A.Y = selectByIndex(cs, curveID, circuit.Accepted, ys[:])
+	dealer.Curve = params
+	message := mimc.Hash(cs, circuit.Bond, circuit.RFQID, cs.Constant(int(SpreadConvention)), circuit.Spread)
+	mustBeCanonical(cs, circuit.SpreadSigned)
+	eddsa.Verify(cs, circuit.SpreadSigned, message, dealer)
+
+	// the benchmark yield is signed by the rate publisher, as a fixing
+	fixingHash := mimc.Hash(cs, circuit.Benchmark, circuit.PricingDate[0], circuit.PricingDate[1], circuit.PricingDate[2], circuit.BenchmarkYield)
+	circuit.PublisherKey.Curve = params
+	mustBeCanonical(cs, circuit.BenchmarkSigned)
+	eddsa.Verify(cs, circuit.BenchmarkSigned, fixingHash, circuit.PublisherKey)
+	yield := cs.Add(circuit.BenchmarkYield, circuit.Spread)
+
+	// the yield is between two points of the table
+	lowYield := selectByIndex(cs, curveID, circuit.Bracket, circuit.Yields[:yieldTableSize-1])
+	highYield := selectByIndex(cs, curveID, circuit.Bracket, circuit.Yields[1:])
+	highPrice := selectByIndex(cs, curveID, circuit.Bracket, circuit.Prices[:yieldTableSize-1])
+	lowPrice := selectByIndex(cs, curveID, circuit.Bracket, circuit.Prices[1:])
+	cs.AssertIsLessOrEqual(lowYield, yield)
+	cs.AssertIsLessOrEqual(yield, highYield)
+	cs.AssertIsLessOrEqual(cs.Add(lowYield, 1), highYield)
+	cs.AssertIsLessOrEqual(lowPrice, highPrice)
+
+	// highPrice - price = floor((highPrice - lowPrice) * (yield - lowYield) / (highYield - lowYield))
+	cs.AssertIsLessOrEqual(circuit.Price, highPrice)
+	drop := cs.Sub(highPrice, circuit.Price)
+	width := cs.Sub(highYield, lowYield)
+	num := cs.Mul(cs.Sub(highPrice, lowPrice), cs.Sub(yield, lowYield))
+	cs.AssertIsLessOrEqual(cs.Mul(drop, width), num)
+	cs.AssertIsLessOrEqual(cs.Sub(num, cs.Mul(drop, width)), cs.Sub(width, 1))
+
+	return nil
+}
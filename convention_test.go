@@ -0,0 +1,208 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestConventionCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("US46625HKC33")
+	bondHash, keys := f.bondHash, f.keys
+	rfqID := big.NewInt(1)
+
+	_, err := SignConventionQuote(keys[0], bondHash, rfqID, SpreadConvention+1, big.NewInt(12500))
+	assert.True(errors.Is(err, errConvention), err)
+
+	// a spread isn't a price: the convention is part of the signed message
+	spread, err := SignConventionQuote(keys[0], bondHash, rfqID, SpreadConvention, big.NewInt(12500))
+	assert.NoError(err)
+	assert.True(spread.Verify(keys[0].Public(), bondHash, rfqID))
+	asPrice := *spread
+	asPrice.Convention = PriceConvention
+	assert.False(asPrice.Verify(keys[0].Public(), bondHash, rfqID))
+	assert.False(spread.Verify(keys[0].Public(), bondHash, big.NewInt(2)))
+
+	assert.True(PriceConvention.Better(big.NewInt(1), big.NewInt(2)))
+	assert.True(SpreadConvention.Better(big.NewInt(2), big.NewInt(1)))
+	assert.False(YieldConvention.Better(big.NewInt(2), big.NewInt(2)))
+
+	var circuit conventionCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(convention QuoteConvention, accepted int, signedAs QuoteConvention, quotes ...int64) *conventionCircuit {
+		var w conventionCircuit
+		w.Bond.Assign(bondHash)
+		w.RFQID.Assign(rfqID)
+		f.assignKeys(&w.PublicKeyCpts)
+		w.Convention.Assign(int(convention))
+		for i, quote := range quotes {
+			signed, err := SignConventionQuote(keys[i], bondHash, rfqID, signedAs, big.NewInt(quote))
+			assert.NoError(err)
+			w.QuoteFromCpts[i].Assign(signed.Quote)
+			assert.NoError(assignSignature(&w.ConventionQuoteSigned[i], signed.Signature))
+		}
+		w.Accepted.Assign(accepted)
+		w.AcceptedQuote.Assign(big.NewInt(quotes[accepted]))
+		return &w
+	}
+
+	// the smallest price is the best, but the largest yield or spread
+	assert.SolvingSucceeded(r1cs, witness(PriceConvention, 1, PriceConvention, 51000000, 50946500, 52011500))
+	assert.SolvingFailed(r1cs, witness(PriceConvention, 2, PriceConvention, 51000000, 50946500, 52011500))
+	assert.SolvingSucceeded(r1cs, witness(YieldConvention, 2, YieldConvention, 51250, 51000, 52000))
+	assert.SolvingSucceeded(r1cs, witness(SpreadConvention, 0, SpreadConvention, 13000, 12500, 12750))
+	assert.SolvingFailed(r1cs, witness(SpreadConvention, 1, SpreadConvention, 13000, 12500, 12750))
+
+	// spreads can't be ranked as prices
+	assert.SolvingFailed(r1cs, witness(PriceConvention, 1, SpreadConvention, 13000, 12500, 12750))
+
+	// nor quotes signed in an unknown convention
+	unknown := witness(SpreadConvention, 0, SpreadConvention, 13000, 12500, 12750)
+	unknown.Convention = frontend.Variable{}
+	unknown.Convention.Assign(int(SpreadConvention + 1))
+	for i, quote := range []int64{13000, 12500, 12750} {
+		sig, err := keys[i].Sign(conventionQuoteHash(bondHash, rfqID, SpreadConvention+1, big.NewInt(quote)), hash.MIMC_BN254.New("seed"))
+		assert.NoError(err)
+		unknown.ConventionQuoteSigned[i] = Signature{}
+		assert.NoError(assignSignature(&unknown.ConventionQuoteSigned[i], sig))
+	}
+	assert.SolvingFailed(r1cs, unknown)
+
+	// nor quotes signed for another RFQ
+	other := witness(SpreadConvention, 0, SpreadConvention, 13000, 12500, 12750)
+	other.RFQID = frontend.Variable{}
+	other.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, other)
+}
+
+func TestSpreadPriceCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("US46625HKC33")
+	bondHash, keys := f.bondHash, f.keys
+	rfqID := big.NewInt(1)
+	otherHash, err := lookupTestBond(f.master, "CA29250NAT24").Hash()
+	assert.NoError(err)
+
+	// clean prices of the bond around the yield of the RFQ
+	point := func(yield, price string) YieldPoint {
+		return YieldPoint{Yield: decimal.RequireFromString(yield), Price: decimal.RequireFromString(price)}
+	}
+	table := PriceYieldTable{
+		point("1.5", "104.12"),
+		point("2", "102.85"),
+		point("2.5", "101.60"),
+		point("3", "100.37"),
+		point("3.5", "99.16"),
+	}
+	yields, prices, err := table.Fields()
+	assert.NoError(err)
+	unsorted := table
+	unsorted[1], unsorted[2] = table[2], table[1]
+	_, _, err = unsorted.Fields()
+	assert.True(errors.Is(err, errYieldTable), err)
+
+	benchmark := bondmath.Fixing{
+		Index: "UST 3Y",
+		Date:  time.Date(2021, 11, 19, 0, 0, 0, 0, time.UTC),
+		Rate:  decimal.RequireFromString("0.8725"),
+	}
+	publisher, err := signature.EDDSA_BN254.New(rand.New(rand.NewSource(7)))
+	assert.NoError(err)
+	benchmarkSigned, err := SignFixing(publisher, benchmark)
+	assert.NoError(err)
+
+	// 0.8725% + 135bp = 2.2225%, between 2% and 2.5%: 102.85 - (102.85 - 101.60) * 0.2225 / 0.5 = 102.29375, rounded up
+	spread := decimal.RequireFromString("1.35")
+	price, err := table.SpreadPrice(benchmark, spread)
+	assert.NoError(err)
+	assert.True(decimal.RequireFromString("102.30").Equal(price), price)
+	_, err = table.SpreadPrice(benchmark, decimal.RequireFromString("3"))
+	assert.True(errors.Is(err, errYieldOutOfTable), err)
+
+	var circuit spreadPriceCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	// the spread accepted from the second dealer
+	witness := func(spread decimal.Decimal, price *big.Int, bracket int) *spreadPriceCircuit {
+		var w spreadPriceCircuit
+		w.Bond.Assign(bondHash)
+		w.RFQID.Assign(rfqID)
+		f.assignKeys(&w.PublicKeyCpts)
+		assignPublicKey(&w.PublisherKey, publisher.Public().Bytes())
+		w.Benchmark.Assign([]byte(benchmark.Index))
+		assignDate(&w.PricingDate, benchmark.Date)
+		w.Spread.Assign(spread.Shift(rateDecimals).BigInt())
+		for i := range yields {
+			w.Yields[i].Assign(yields[i])
+			w.Prices[i].Assign(prices[i])
+		}
+		w.Price.Assign(price)
+		w.BenchmarkYield.Assign(benchmark.Rate.Shift(rateDecimals).BigInt())
+		assert.NoError(assignSignature(&w.BenchmarkSigned, benchmarkSigned))
+		signed, err := SignConventionQuote(keys[1], bondHash, rfqID, SpreadConvention, spread.Shift(rateDecimals).BigInt())
+		assert.NoError(err)
+		assert.NoError(assignSignature(&w.SpreadSigned, signed.Signature))
+		w.Accepted.Assign(1)
+		w.Bracket.Assign(bracket)
+		return &w
+	}
+
+	assert.SolvingSucceeded(r1cs, witness(spread, big.NewInt(10230), 1))
+
+	// the price is rounded up, and read between the points around the yield
+	assert.SolvingFailed(r1cs, witness(spread, big.NewInt(10229), 1))
+	assert.SolvingFailed(r1cs, witness(spread, big.NewInt(10231), 1))
+	assert.SolvingFailed(r1cs, witness(spread, big.NewInt(10230), 2))
+
+	// on a point of the table, the price is the one of the table
+	assert.SolvingSucceeded(r1cs, witness(decimal.RequireFromString("1.6275"), big.NewInt(10160), 1))
+	assert.SolvingSucceeded(r1cs, witness(decimal.RequireFromString("1.6275"), big.NewInt(10160), 2))
+
+	// the benchmark yield is the one signed by the publisher
+	bad := witness(spread, big.NewInt(10230), 1)
+	bad.BenchmarkYield = frontend.Variable{}
+	bad.BenchmarkYield.Assign(8726)
+	assert.SolvingFailed(r1cs, bad)
+
+	// the spread is the one signed by the dealer for the bond, in the spread convention
+	bad = witness(spread, big.NewInt(10229), 1)
+	bad.Bond = frontend.Variable{}
+	bad.Bond.Assign(otherHash)
+	assert.SolvingFailed(r1cs, bad)
+
+	bad = witness(spread, big.NewInt(10230), 1)
+	bad.RFQID = frontend.Variable{}
+	bad.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, bad)
+
+	bad = witness(spread, big.NewInt(10229), 1)
+	bad.Accepted = frontend.Variable{}
+	bad.Accepted.Assign(0)
+	assert.SolvingFailed(r1cs, bad)
+
+	asYield, err := SignConventionQuote(keys[1], bondHash, rfqID, YieldConvention, spread.Shift(rateDecimals).BigInt())
+	assert.NoError(err)
+	bad = witness(spread, big.NewInt(10229), 1)
+	bad.SpreadSigned = Signature{}
+	assert.NoError(assignSignature(&bad.SpreadSigned, asYield.Signature))
+	assert.SolvingFailed(r1cs, bad)
+}
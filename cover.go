@@ -0,0 +1,88 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+var errNoCover = errors.New("fewer than two dealers quoted, the RFQ has no cover")
+
+// Cover returns the slots of the accepted quote and of the cover, the second-best quote, among quotes
+// that aren't passes. Equal quotes go to the first slot, as in bondCircuit.
+func Cover(quotes []*big.Int) (winner, cover int, err error) {
+	winner, cover = -1, -1
+	for i, quote := range quotes {
+		if quote.Cmp(PassQuote) == 0 {
+			continue
+		}
+		switch {
+		case winner < 0 || quote.Cmp(quotes[winner]) < 0:
+			winner, cover = i, winner
+		case cover < 0 || quote.Cmp(quotes[cover]) < 0:
+			cover = i
+		}
+	}
+	if cover < 0 {
+		return 0, 0, errNoCover
+	}
+	return winner, cover, nil
+}
+
+// coverCircuit is the proof the initiator gives the winning dealer of a RFQ proven with bondCircuit:
+// it discloses the cover, or only the spread between the cover and the accepted quote, without the
+// slot of the dealer who quoted it. The quotes are those of the bondCircuit proof, as they open the
+// same public commitments for the same RFQ and bond.
+type coverCircuit struct {
+	AcceptedQuoteQuery  frontend.Variable    `gnark:",public"`  // accepted quote of the bondCircuit proof
+	PublicKeyCpts       [3]PublicKey         `gnark:",public"`  // counterparties asked for a quote
+	Bond                frontend.Variable    `gnark:",public"`  // hash of the bond attributes
+	RFQID               frontend.Variable    `gnark:",public"`  // unique to the RFQ
+	QuoteCommitments    [3]frontend.Variable `gnark:",public"`  // sealed quotes of the dealers
+	SpreadOnly          frontend.Variable    `gnark:",public"`  // 1 to disclose cover - accepted quote only
+	Disclosed           frontend.Variable    `gnark:",public"`  // cover, or its spread to the accepted quote
+	QuoteFromCpts       [3]frontend.Variable `gnark:",private"` // clean price * size, in cents
+	BondQuoteSignedCpts [3]Signature         `gnark:",private"` // Sign(Bond hash, RFQID, quote)
+	QuoteBlindings      [3]frontend.Variable `gnark:",private"` // blinding factors of the commitments
+	WinnerIndex         frontend.Variable    `gnark:",private"` // slot of the accepted quote
+	CoverIndex          frontend.Variable    `gnark:",private"` // slot of the cover
+}
+
+func (circuit *coverCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	// the quotes ranked in the bondCircuit proof: signed by the dealers and sealed for the RFQ
+	for i := range circuit.QuoteFromCpts {
+		mustBeSignedQuote(cs, mimc, params, circuit.Bond, circuit.RFQID, circuit.QuoteFromCpts[i], circuit.BondQuoteSignedCpts[i], circuit.PublicKeyCpts[i])
+		commitment := quoteCommitment(cs, mimc, circuit.RFQID, circuit.Bond, circuit.QuoteFromCpts[i], circuit.BondQuoteSignedCpts[i], circuit.QuoteBlindings[i])
+		cs.AssertIsEqual(circuit.QuoteCommitments[i], commitment)
+	}
+
+	// the accepted quote is the best one
+	accepted := selectByIndex(cs, curveID, circuit.WinnerIndex, circuit.QuoteFromCpts[:])
+	cs.AssertIsEqual(accepted, circuit.AcceptedQuoteQuery)
+	mustBeBestQuote(cs, curveID, accepted, circuit.QuoteFromCpts)
+
+	// and the cover the best of the other quotes, from another slot and not a pass
+	cs.AssertIsEqual(cs.IsZero(cs.Sub(circuit.WinnerIndex, circuit.CoverIndex), curveID), 0)
+	cover := selectByIndex(cs, curveID, circuit.CoverIndex, circuit.QuoteFromCpts[:])
+	cs.AssertIsEqual(cs.IsZero(cs.Sub(cover, cs.Constant(PassQuote)), curveID), 0)
+	for i := range circuit.QuoteFromCpts {
+		isWinner := cs.IsZero(cs.Sub(circuit.WinnerIndex, i), curveID)
+		cs.AssertIsLessOrEqual(cover, cs.Select(isWinner, cover, circuit.QuoteFromCpts[i]))
+	}
+
+	cs.AssertIsBoolean(circuit.SpreadOnly)
+	cs.AssertIsEqual(circuit.Disclosed, cs.Select(circuit.SpreadOnly, cs.Sub(cover, accepted), cover))
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestCover(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("CA29250NAT24")
+	rfqID := big.NewInt(1)
+
+	low, mid, high := big.NewInt(50946500), big.NewInt(51000000), big.NewInt(52011500)
+
+	winner, cover, err := Cover([]*big.Int{high, low, mid})
+	assert.NoError(err)
+	assert.Equal(1, winner)
+	assert.Equal(2, cover)
+	winner, cover, err = Cover([]*big.Int{low, PassQuote, low})
+	assert.NoError(err)
+	assert.Equal(0, winner)
+	assert.Equal(2, cover)
+	_, _, err = Cover([]*big.Int{PassQuote, mid, PassQuote})
+	assert.True(errors.Is(err, errNoCover), err)
+
+	var circuit coverCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	// witness seals the quotes for the RFQ and discloses the quote in slot coverIndex
+	witness := func(quotes [3]*big.Int, winnerIndex, coverIndex int, spreadOnly bool) *coverCircuit {
+		var w coverCircuit
+		w.Bond.Assign(f.bondHash)
+		w.RFQID.Assign(rfqID)
+		f.assignKeys(&w.PublicKeyCpts)
+		for i, quote := range quotes {
+			signed, err := NewLocalSigner(f.keys[i], nil).SignQuote(f.bond, rfqID, quote)
+			assert.NoError(err)
+			reveal, commitment, err := SealQuote(f.keys[i].Public(), rfqID, f.bondHash, signed)
+			assert.NoError(err)
+			assert.NoError(assignSignature(&w.BondQuoteSignedCpts[i], signed.BondSignature))
+			w.QuoteFromCpts[i].Assign(quote)
+			w.QuoteCommitments[i].Assign(commitment)
+			w.QuoteBlindings[i].Assign(reveal.Blinding)
+		}
+		w.AcceptedQuoteQuery.Assign(quotes[winnerIndex])
+		w.WinnerIndex.Assign(winnerIndex)
+		w.CoverIndex.Assign(coverIndex)
+		if spreadOnly {
+			w.SpreadOnly.Assign(1)
+			w.Disclosed.Assign(new(big.Int).Sub(quotes[coverIndex], quotes[winnerIndex]))
+		} else {
+			w.SpreadOnly.Assign(0)
+			w.Disclosed.Assign(quotes[coverIndex])
+		}
+		return &w
+	}
+
+	// the winner learns the cover, or only how far it was
+	assert.SolvingSucceeded(r1cs, witness([3]*big.Int{high, low, mid}, 1, 2, false))
+	assert.SolvingSucceeded(r1cs, witness([3]*big.Int{high, low, mid}, 1, 2, true))
+	assert.SolvingSucceeded(r1cs, witness([3]*big.Int{low, PassQuote, low}, 0, 2, true))
+
+	// the cover is the second-best quote, not the third nor the accepted one
+	assert.SolvingFailed(r1cs, witness([3]*big.Int{high, low, mid}, 1, 0, false))
+	assert.SolvingFailed(r1cs, witness([3]*big.Int{high, low, mid}, 1, 1, true))
+
+	// nor a pass, when only one dealer quoted
+	assert.SolvingFailed(r1cs, witness([3]*big.Int{PassQuote, low, PassQuote}, 1, 0, false))
+
+	// and the accepted quote is still the best one
+	assert.SolvingFailed(r1cs, witness([3]*big.Int{high, low, mid}, 2, 0, false))
+
+	// the disclosed value is the one of the cover
+	bad := witness([3]*big.Int{high, low, mid}, 1, 2, true)
+	bad.Disclosed = frontend.Variable{}
+	bad.Disclosed.Assign(mid)
+	assert.SolvingFailed(r1cs, bad)
+}
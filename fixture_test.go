@@ -0,0 +1,61 @@
+package financial
+
+import (
+	"math/big"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+)
+
+// rfqFixture is a bond of the test security master and the keys of the three dealers asked to quote it
+type rfqFixture struct {
+	master   SecurityMaster
+	bond     *Bond
+	bondHash []byte
+	keys     [3]signature.Signer
+}
+
+// newRFQFixture looks up the bond with that ISIN in the test security master and generates the dealer keys
+func newRFQFixture(isin string) *rfqFixture {
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	if err != nil {
+		panic(err)
+	}
+	f := &rfqFixture{master: master, bond: lookupTestBond(master, isin)}
+	if f.bondHash, err = f.bond.Hash(); err != nil {
+		panic(err)
+	}
+	for i := range f.keys {
+		if f.keys[i], err = keystore.Generate(); err != nil {
+			panic(err)
+		}
+	}
+	return f
+}
+
+// dealers returns the public keys of the dealers, in slot order
+func (f *rfqFixture) dealers() []signature.PublicKey {
+	dealers := make([]signature.PublicKey, len(f.keys))
+	for i, key := range f.keys {
+		dealers[i] = key.Public()
+	}
+	return dealers
+}
+
+// assignKeys sets the dealer keys in the public keys of a witness
+func (f *rfqFixture) assignKeys(keys *[3]PublicKey) {
+	for i, key := range f.keys {
+		assignPublicKey(&keys[i], key.Public().Bytes())
+	}
+}
+
+// quoteAmount encodes a clean price (92.63) for the size of the bond as the quotes: price / 100 * size in cents
+func quoteAmount(bond *Bond, price string) *big.Int {
+	size, err := decimal.NewFromString(bond.Size)
+	if err != nil {
+		panic(err)
+	}
+	return decimal.RequireFromString(price).Mul(size).BigInt()
+}
@@ -0,0 +1,143 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+var errInvalidFixing = errors.New("fixing rate must be positive with at most 4 decimals")
+
+// CouponReset holds the private values proving the coupon rate of a floating rate note set on a reset date
+type CouponReset struct {
+	PeriodMonths      frontend.Variable // 12 / coupon frequency
+	PeriodsToMaturity frontend.Variable // coupon periods from the reset date to maturity
+	Fixing            frontend.Variable // rate of the reference index on the reset date, scaled by 10^rateDecimals
+	FixingSigned      Signature         // Sign(fixing hash) by the rate publisher
+}
+
+// mustReset proves the coupon rate set on the reset date (year, month, day) of a floating rate note encoded
+// as in Bond.Fields, and returns it scaled by 10^rateDecimals: the fixing signed by publisher plus the spread.
+func (r *CouponReset) mustReset(cs *frontend.ConstraintSystem, curveID ecc.ID, hash mimc.MiMC,
+	bond [bondFieldsSize]frontend.Variable, publisher PublicKey, reset [3]frontend.Variable) frontend.Variable {
+
+	index, spread, couponDay := bond[termsField], bond[termsField+1], bond[9]
+
+	// the bond is a floating rate note
+	cs.AssertIsEqual(isInstrument(cs, curveID, bond, FRN), 1)
+
+	// coupons are reset on coupon dates before maturity
+	mustBeCouponMonth(cs, bond, reset[0], reset[1], r.PeriodMonths, r.PeriodsToMaturity)
+	cs.AssertIsEqual(reset[2], couponDay)
+	cs.AssertIsEqual(cs.IsZero(r.PeriodsToMaturity, curveID), 0)
+
+	// the fixing of the index on the reset date is signed by the rate publisher
+	fixingHash := hash.Hash(cs, index, reset[0], reset[1], reset[2], r.Fixing)
+	mustBeCanonical(cs, r.FixingSigned)
+	eddsa.Verify(cs, r.FixingSigned, fixingHash, publisher)
+
+	return cs.Add(r.Fixing, spread)
+}
+
+// Assign sets the witness values proving the coupon rate of bond fixed by fixing, signed by the rate publisher.
+// It returns the coupon rate scaled by 10^rateDecimals.
+func (r *CouponReset) Assign(bond *Bond, fixing bondmath.Fixing, fixingSigned []byte) (*big.Int, error) {
+	coupon := &bond.Coupon
+
+	rate, err := coupon.Reset(fixing)
+	if err != nil {
+		return nil, err
+	}
+	fixingRate, err := fixingField(fixing)
+	if err != nil {
+		return nil, err
+	}
+	periodMonths, err := coupon.PeriodMonths()
+	if err != nil {
+		return nil, err
+	}
+	_, _, periods, err := coupon.Period(fixing.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	r.PeriodMonths.Assign(periodMonths)
+	r.PeriodsToMaturity.Assign(periods)
+	r.Fixing.Assign(fixingRate)
+	if err := assignSignature(&r.FixingSigned, fixingSigned); err != nil {
+		return nil, err
+	}
+
+	return rate.Shift(rateDecimals).BigInt(), nil
+}
+
+// fixingField returns the fixing rate scaled by 10^rateDecimals
+func fixingField(fixing bondmath.Fixing) (*big.Int, error) {
+	rate := fixing.Rate.Shift(rateDecimals)
+	if !rate.Equal(rate.Truncate(0)) || rate.Sign() < 0 {
+		return nil, errInvalidFixing
+	}
+	if len(fixing.Index) == 0 || len(fixing.Index) > textChunkSize {
+		return nil, errIndexTooLong
+	}
+	return rate.BigInt(), nil
+}
+
+// FixingHash returns the message a rate publisher signs for a fixing: the MiMC hash of the index,
+// the date (year, month, day) and the rate
+func FixingHash(fixing bondmath.Fixing) ([]byte, error) {
+	rate, err := fixingField(fixing)
+	if err != nil {
+		return nil, err
+	}
+	year, month, day := fixing.Date.Date()
+	return hashFields(new(big.Int).SetBytes([]byte(fixing.Index)),
+		big.NewInt(int64(year)), big.NewInt(int64(month)), big.NewInt(int64(day)), rate), nil
+}
+
+// SignFixing signs a fixing with the key of a rate publisher
+func SignFixing(publisher signature.Signer, fixing bondmath.Fixing) ([]byte, error) {
+	msg, err := FixingHash(fixing)
+	if err != nil {
+		return nil, err
+	}
+	return publisher.Sign(msg, hash.MIMC_BN254.New("seed"))
+}
+
+// frnCouponCircuit proves the coupon of a floating rate note for the period starting on a reset date
+// is the reference rate fixing signed by a rate publisher plus the spread of the bond.
+// Its Bond is the hash used in bondCircuit, so FRN trades are confirmed with the RFQ proof.
+type frnCouponCircuit struct {
+	Bond           frontend.Variable                 `gnark:",public"`  // hash of the bond attributes
+	PublisherKey   PublicKey                         `gnark:",public"`  // public key of the rate publisher
+	ResetDate      [3]frontend.Variable              `gnark:",public"`  // year, month, day
+	Coupon         frontend.Variable                 `gnark:",public"`  // coupon rate, scaled by 10^rateDecimals
+	BondAttributes [bondFieldsSize]frontend.Variable `gnark:",private"` // as in Bond.Fields
+	Reset          CouponReset                       `gnark:",private"`
+}
+
+func (circuit *frnCouponCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	cs.AssertIsEqual(circuit.Bond, mimc.Hash(cs, circuit.BondAttributes[:]...))
+
+	circuit.PublisherKey.Curve = params
+	rate := circuit.Reset.mustReset(cs, curveID, mimc, circuit.BondAttributes, circuit.PublisherKey, circuit.ResetDate)
+	cs.AssertIsEqual(circuit.Coupon, rate)
+
+	return nil
+}
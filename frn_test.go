@@ -0,0 +1,109 @@
+package financial
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestFrnCouponCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit frnCouponCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	bond := &Bond{
+		Isin:   "US89114QCR74",
+		Size:   "600000",
+		Ticker: "The Toronto-Dominion VAR 03/04/2024",
+		Type:   FRN,
+		Coupon: bondmath.Coupon{
+			Rate:      decimal.RequireFromString("0.5125"),
+			Frequency: 4,
+			DayCount:  bondmath.Actual360,
+			Maturity:  time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			Floating:  &bondmath.FloatingRate{Index: "SOFR", Spread: decimal.RequireFromString("0.48")},
+		},
+	}
+	fixing := bondmath.Fixing{
+		Index: "SOFR",
+		Date:  time.Date(2021, 9, 4, 0, 0, 0, 0, time.UTC),
+		Rate:  decimal.RequireFromString("0.0325"),
+	}
+
+	publisher, err := signature.EDDSA_BN254.New(rand.New(rand.NewSource(7)))
+	assert.NoError(err)
+	fixingSigned, err := SignFixing(publisher, fixing)
+	assert.NoError(err)
+
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+	bondFields, err := bond.Fields()
+	assert.NoError(err)
+	floating := bondFromFields(bondFields).Coupon.Floating
+	assert.Equal("SOFR", floating.Index)
+	assert.True(floating.Spread.Equal(bond.Coupon.Floating.Spread))
+
+	witness := func(bond *Bond, fixing bondmath.Fixing, fixingSigned []byte) *frnCouponCircuit {
+		var w frnCouponCircuit
+		w.Bond.Assign(bondHash)
+		for i := range bondFields {
+			w.BondAttributes[i].Assign(bondFields[i])
+		}
+		assignPublicKey(&w.PublisherKey, publisher.Public().Bytes())
+		assignDate(&w.ResetDate, fixing.Date)
+		rate, err := w.Reset.Assign(bond, fixing, fixingSigned)
+		assert.NoError(err)
+		w.Coupon.Assign(rate)
+		return &w
+	}
+
+	good := witness(bond, fixing, fixingSigned)
+	assert.SolvingSucceeded(r1cs, good)
+
+	// the coupon is the fixing plus the spread
+	wrongCoupon := witness(bond, fixing, fixingSigned)
+	wrongCoupon.Coupon = frontend.Variable{}
+	wrongCoupon.Coupon.Assign(5125 + 1)
+	assert.SolvingFailed(r1cs, wrongCoupon)
+
+	// the fixing must be signed by the rate publisher
+	other, err := signature.EDDSA_BN254.New(rand.New(rand.NewSource(8)))
+	assert.NoError(err)
+	otherSigned, err := SignFixing(other, fixing)
+	assert.NoError(err)
+	assert.SolvingFailed(r1cs, witness(bond, fixing, otherSigned))
+
+	// a higher fixing than the signed one is rejected
+	higher := fixing
+	higher.Rate = decimal.RequireFromString("0.05")
+	assert.SolvingFailed(r1cs, witness(bond, higher, fixingSigned))
+
+	// the reset date must be a coupon date
+	offReset := witness(bond, fixing, fixingSigned)
+	offReset.ResetDate[2] = frontend.Variable{}
+	offReset.ResetDate[2].Assign(5)
+	assert.SolvingFailed(r1cs, offReset)
+
+	// a fixed coupon bond has no reset
+	fixed := *bond
+	fixed.Coupon.Floating = nil
+	_, err = new(CouponReset).Assign(&fixed, fixing, fixingSigned)
+	assert.Error(err)
+
+	// nor a witness from a malformed fixing signature
+	_, err = new(CouponReset).Assign(bond, fixing, fixingSigned[:len(fixingSigned)-1])
+	assert.True(errors.Is(err, errSignatureSize), err)
+}
@@ -3,7 +3,8 @@ module bloconuts/v0
 go 1.16
 
 require (
-	github.com/consensys/gnark v0.4.0 // indirect
-	github.com/consensys/gnark-crypto v0.4.1-0.20210428083642-6bd055b79906 // indirect
-	github.com/shopspring/decimal v1.2.0 // indirect
+	github.com/consensys/gnark v0.4.0
+	github.com/consensys/gnark-crypto v0.4.1-0.20210428083642-6bd055b79906
+	github.com/shopspring/decimal v1.2.0
+	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 )
@@ -0,0 +1,182 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/shopspring/decimal"
+)
+
+// InstrumentType is the kind of a bond. It decides which terms are committed in the last
+// bondTermsSize fields of the bond and which rules the circuits apply:
+//
+//	Vanilla    0, 0
+//	Callable   call schedule hash, 0
+//	Puttable   put schedule hash, 0
+//	Perpetual  call schedule hash (of no calls if not callable), 0
+//	FRN        reference index, spread
+//	StepUp     step schedule hash, call schedule hash (of no calls if not callable)
+type InstrumentType int
+
+const (
+	Vanilla   InstrumentType = iota // fixed coupon, redeemed at maturity
+	Callable                        // the issuer can redeem the bond on its call dates
+	Puttable                        // the holder can sell the bond back on its put dates
+	Perpetual                       // no maturity, the coupon schedule ends in bondmath.PerpetualYear
+	FRN                             // floating rate note, the coupon is a reference index fixing plus a spread
+	StepUp                          // the coupon rate changes on the step dates
+)
+
+// bondTermsSize is the number of fields holding the terms specific to the instrument type
+const bondTermsSize = 2
+
+var instrumentTypes = [...]string{"Vanilla", "Callable", "Puttable", "Perpetual", "FRN", "StepUp"}
+
+var (
+	errInstrumentType  = errors.New("unknown instrument type")
+	errInstrumentTerms = errors.New("bond terms don't match the instrument type")
+	errInvalidPutPrice = errors.New("put price must be positive with at most 4 decimals")
+)
+
+func (t InstrumentType) String() string {
+	if t < 0 || int(t) >= len(instrumentTypes) {
+		return "unknown"
+	}
+	return instrumentTypes[t]
+}
+
+// ParseInstrumentType reads an instrument type as written by String, ignoring case
+func ParseInstrumentType(s string) (InstrumentType, error) {
+	for i, name := range instrumentTypes {
+		if strings.EqualFold(s, name) {
+			return InstrumentType(i), nil
+		}
+	}
+	return 0, errInstrumentType
+}
+
+// terms checks the bond only has the terms of its type and encodes them as field elements
+func (bond *Bond) terms() ([bondTermsSize]*big.Int, error) {
+	terms := [bondTermsSize]*big.Int{big.NewInt(0), big.NewInt(0)}
+	coupon := &bond.Coupon
+
+	hasCalls, hasPuts, hasSteps, hasFloating := len(bond.Calls) > 0, len(bond.Puts) > 0, len(coupon.Steps) > 0, coupon.Floating != nil
+	perpetual := coupon.Maturity.Year() == bondmath.PerpetualYear
+
+	var valid bool
+	switch bond.Type {
+	case Vanilla:
+		valid = !hasCalls && !hasPuts && !hasSteps && !hasFloating
+	case Callable:
+		valid = hasCalls && !hasPuts && !hasSteps && !hasFloating
+	case Puttable:
+		valid = !hasCalls && hasPuts && !hasSteps && !hasFloating
+	case Perpetual:
+		valid = perpetual && !hasPuts && !hasSteps && !hasFloating
+	case FRN:
+		valid = !hasCalls && !hasPuts && !hasSteps && hasFloating
+	case StepUp:
+		valid = !hasPuts && hasSteps && !hasFloating
+	default:
+		return terms, errInstrumentType
+	}
+	if !valid || (perpetual && bond.Type != Perpetual) {
+		return terms, errInstrumentTerms
+	}
+
+	switch bond.Type {
+	case Callable, Perpetual:
+		calls, err := bond.CallScheduleHash()
+		if err != nil {
+			return terms, err
+		}
+		terms[0].SetBytes(calls)
+	case Puttable:
+		puts, err := bond.PutScheduleHash()
+		if err != nil {
+			return terms, err
+		}
+		terms[0].SetBytes(puts)
+	case FRN:
+		floating := coupon.Floating
+		if len(floating.Index) == 0 || len(floating.Index) > textChunkSize {
+			return terms, errIndexTooLong
+		}
+		spread := floating.Spread.Shift(rateDecimals)
+		if !spread.Equal(spread.Truncate(0)) || spread.Sign() < 0 {
+			return terms, errInvalidSpread
+		}
+		terms[0].SetBytes([]byte(floating.Index))
+		terms[1] = spread.BigInt()
+	case StepUp:
+		steps, err := bond.StepScheduleHash()
+		if err != nil {
+			return terms, err
+		}
+		calls, err := bond.CallScheduleHash()
+		if err != nil {
+			return terms, err
+		}
+		terms[0].SetBytes(steps)
+		terms[1].SetBytes(calls)
+	}
+	return terms, nil
+}
+
+// setTerms is the inverse of terms, except for the schedules which are only known by their hash
+func (bond *Bond) setTerms(terms [bondTermsSize]*big.Int) {
+	if bond.Type == FRN {
+		bond.Coupon.Floating = &bondmath.FloatingRate{
+			Index:  string(terms[0].Bytes()),
+			Spread: decimal.NewFromBigInt(terms[1], -rateDecimals),
+		}
+	}
+}
+
+// PutScheduleHash returns the MiMC hash of the put dates (year, month, day) and prices
+func (bond *Bond) PutScheduleHash() ([]byte, error) {
+	puts := make([]*big.Int, 0, 4*len(bond.Puts))
+	for _, put := range bond.Puts {
+		price := put.Price.Shift(rateDecimals)
+		if !price.Equal(price.Truncate(0)) || price.Sign() <= 0 {
+			return nil, errInvalidPutPrice
+		}
+		year, month, day := put.Date.Date()
+		puts = append(puts, big.NewInt(int64(year)), big.NewInt(int64(month)), big.NewInt(int64(day)), price.BigInt())
+	}
+	return hashFields(puts...), nil
+}
+
+// mustBeInstrumentType checks the type field of bond, encoded as in Bond.Fields, is instrumentType
+// and a known instrument type
+func mustBeInstrumentType(cs *frontend.ConstraintSystem, bond [bondFieldsSize]frontend.Variable, instrumentType frontend.Variable) {
+	cs.AssertIsEqual(bond[typeField], instrumentType)
+	cs.AssertIsLessOrEqual(instrumentType, int(StepUp))
+}
+
+// isInstrument returns 1 if bond, encoded as in Bond.Fields, is of type t, 0 otherwise
+func isInstrument(cs *frontend.ConstraintSystem, curveID ecc.ID, bond [bondFieldsSize]frontend.Variable, t InstrumentType) frontend.Variable {
+	return cs.IsZero(cs.Sub(bond[typeField], int(t)), curveID)
+}
+
+// callScheduleTerm returns the call schedule hash of a callable, perpetual or step-up bond
+// and checks the bond is of one of those types
+func callScheduleTerm(cs *frontend.ConstraintSystem, curveID ecc.ID, bond [bondFieldsSize]frontend.Variable) frontend.Variable {
+	isStepUp := isInstrument(cs, curveID, bond, StepUp)
+	hasCalls := cs.Add(isInstrument(cs, curveID, bond, Callable), isInstrument(cs, curveID, bond, Perpetual), isStepUp)
+	cs.AssertIsEqual(hasCalls, 1)
+	return cs.Select(isStepUp, bond[termsField+1], bond[termsField])
+}
+
+// stepScheduleTerm returns the step schedule hash of a step-up bond,
+// and the hash of an empty schedule for other bonds
+func stepScheduleTerm(cs *frontend.ConstraintSystem, curveID ecc.ID, bond [bondFieldsSize]frontend.Variable) frontend.Variable {
+	noSteps, _ := stepFields(nil)
+	empty := new(big.Int).SetBytes(hashFields(noSteps...))
+	return cs.Select(isInstrument(cs, curveID, bond, StepUp), bond[termsField], empty)
+}
@@ -0,0 +1,89 @@
+package financial
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+func TestInstrumentTerms(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	coupon := bondmath.Coupon{
+		Rate:      decimal.RequireFromString("3"),
+		Frequency: 2,
+		DayCount:  bondmath.Thirty360,
+		Maturity:  time.Date(2031, 6, 15, 0, 0, 0, 0, time.UTC),
+	}
+	calls := bondmath.CallSchedule{{Date: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), Price: decimal.NewFromInt(100)}}
+	puts := bondmath.PutSchedule{{Date: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), Price: decimal.NewFromInt(100)}}
+	steps := bondmath.StepSchedule{{Date: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("4")}}
+	floating := &bondmath.FloatingRate{Index: "SOFR", Spread: decimal.RequireFromString("0.75")}
+	perpetual := coupon
+	perpetual.Maturity = bondmath.PerpetualMaturity(coupon.Maturity)
+
+	bond := func(instrumentType InstrumentType, coupon bondmath.Coupon, calls bondmath.CallSchedule, puts bondmath.PutSchedule) *Bond {
+		return &Bond{Isin: "US0000000002", Size: "100000", Ticker: "TEST", Type: instrumentType, Coupon: coupon, Calls: calls, Puts: puts}
+	}
+	withSteps, withFloating := coupon, coupon
+	withSteps.Steps = steps
+	withFloating.Floating = floating
+
+	valid := []*Bond{
+		bond(Vanilla, coupon, nil, nil),
+		bond(Callable, coupon, calls, nil),
+		bond(Puttable, coupon, nil, puts),
+		bond(Perpetual, perpetual, nil, nil),
+		bond(Perpetual, perpetual, calls, nil),
+		bond(FRN, withFloating, nil, nil),
+		bond(StepUp, withSteps, nil, nil),
+		bond(StepUp, withSteps, calls, nil),
+	}
+	hashes := make(map[string]bool)
+	for _, b := range valid {
+		fields, err := b.Fields()
+		assert.NoError(err, b.Type.String())
+		assert.Equal(int64(b.Type), fields[typeField].Int64())
+
+		decoded := bondFromFields(fields)
+		assert.Equal(b.Type, decoded.Type)
+		assert.Equal(b.Coupon.Floating == nil, decoded.Coupon.Floating == nil)
+
+		hash, err := b.Hash()
+		assert.NoError(err)
+		hashes[string(hash)] = true
+	}
+	assert.Equal(len(valid), len(hashes), "every layout commits to different terms")
+
+	invalid := []*Bond{
+		bond(Vanilla, coupon, calls, nil),
+		bond(Callable, coupon, nil, nil),
+		bond(Callable, withSteps, calls, nil),
+		bond(Puttable, coupon, calls, puts),
+		bond(Perpetual, coupon, nil, nil),
+		bond(Vanilla, perpetual, nil, nil),
+		bond(FRN, coupon, nil, nil),
+		bond(FRN, withFloating, calls, nil),
+		bond(StepUp, coupon, calls, nil),
+		bond(StepUp, withSteps, nil, puts),
+		bond(InstrumentType(6), coupon, nil, nil),
+	}
+	for _, b := range invalid {
+		_, err := b.Fields()
+		assert.Error(err, b.Type.String())
+	}
+
+	for _, name := range []string{"Vanilla", "callable", "PUTTABLE", "Perpetual", "FRN", "StepUp"} {
+		instrumentType, err := ParseInstrumentType(name)
+		assert.NoError(err)
+		assert.True(strings.EqualFold(name, instrumentType.String()))
+	}
+	_, err := ParseInstrumentType("Convertible")
+	assert.Error(err)
+}
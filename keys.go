@@ -0,0 +1,78 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+var (
+	errNotBN254Key     = errors.New("dealer key isn't an eddsa bn254 key")
+	errKeyNotOnCurve   = errors.New("dealer key isn't a point of the curve")
+	errLowOrderKey     = errors.New("dealer key is in the small subgroup of the curve")
+	errDuplicateDealer = errors.New("dealer key is used in two slots")
+)
+
+// cofactor of the BN254 twisted Edwards curve, the order of its small subgroup
+var cofactor = big.NewInt(8)
+
+// validateKey checks a key is on the curve and outside its small subgroup, as mustBeValidKey does
+func validateKey(key signature.PublicKey) (*edwardsbn254.PointAffine, error) {
+	pk, ok := key.(*eddsabn254.PublicKey)
+	if !ok {
+		return nil, errNotBN254Key
+	}
+	if !pk.A.IsOnCurve() {
+		return nil, errKeyNotOnCurve
+	}
+	var cleared, neutral edwardsbn254.PointAffine
+	cleared.ScalarMul(&pk.A, cofactor)
+	neutral.Y.SetOne()
+	if cleared.Equal(&neutral) {
+		return nil, errLowOrderKey
+	}
+	return &pk.A, nil
+}
+
+// assignDealerKeys checks the dealer keys are valid and pairwise different, as bondCircuit does,
+// and sets them in the witness
+func assignDealerKeys(witness *[3]PublicKey, keys [3]signature.PublicKey) error {
+	var points [3]*edwardsbn254.PointAffine
+	for i, key := range keys {
+		point, err := validateKey(key)
+		if err != nil {
+			return err
+		}
+		for j := 0; j < i; j++ {
+			if points[j].Equal(point) {
+				return errDuplicateDealer
+			}
+		}
+		points[i] = point
+	}
+	for i, point := range points {
+		witness[i].A.X.Assign(point.X.ToBigIntRegular(new(big.Int)))
+		witness[i].A.Y.Assign(point.Y.ToBigIntRegular(new(big.Int)))
+	}
+	return nil
+}
+
+// mustBeValidKey asserts a public key is on the curve and outside its small subgroup:
+// 8 times the key isn't the neutral point (0, 1)
+func mustBeValidKey(cs *frontend.ConstraintSystem, curve twistededwards.EdCurve, key PublicKey) {
+	key.A.MustBeOnCurve(cs, curve)
+
+	var cleared twistededwards.Point
+	cleared.Double(cs, &key.A, curve)
+	cleared.Double(cs, &cleared, curve)
+	cleared.Double(cs, &cleared, curve)
+
+	isZeroX := cs.IsZero(cleared.X, curve.ID)
+	isOneY := cs.IsZero(cs.Sub(cleared.Y, 1), curve.ID)
+	cs.AssertIsEqual(cs.And(isZeroX, isOneY), 0)
+}
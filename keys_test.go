@@ -0,0 +1,97 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+type dealerKeysCircuit struct {
+	PublicKeys [3]PublicKey `gnark:",public"`
+}
+
+func (circuit *dealerKeysCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	for i := range circuit.PublicKeys {
+		mustBeValidKey(cs, params, circuit.PublicKeys[i])
+	}
+	mustBeDistinctKeys(cs, curveID, circuit.PublicKeys)
+	return nil
+}
+
+// rawKey is a public key with these coordinates, whether or not they are a valid key
+func rawKey(x, y uint64) signature.PublicKey {
+	var pk eddsabn254.PublicKey
+	pk.A.X.SetUint64(x)
+	pk.A.Y.SetUint64(y)
+	return &pk
+}
+
+func TestDealerKeys(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit dealerKeysCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	var keys [3]signature.PublicKey
+	for i := range keys {
+		key, err := keystore.Generate()
+		assert.NoError(err)
+		keys[i] = key.Public()
+	}
+
+	// witness sets the keys as they are, for the circuit to check them
+	witness := func(keys [3]signature.PublicKey) *dealerKeysCircuit {
+		var w dealerKeysCircuit
+		for i, key := range keys {
+			pk := key.(*eddsabn254.PublicKey)
+			w.PublicKeys[i].A.X.Assign(pk.A.X.ToBigIntRegular(new(big.Int)))
+			w.PublicKeys[i].A.Y.Assign(pk.A.Y.ToBigIntRegular(new(big.Int)))
+		}
+		return &w
+	}
+
+	var good dealerKeysCircuit
+	assert.NoError(assignDealerKeys(&good.PublicKeys, keys))
+	assert.SolvingSucceeded(r1cs, &good)
+
+	var minusOne fr.Element
+	minusOne.SetOne().Neg(&minusOne)
+	orderTwo := rawKey(0, 0)
+	orderTwo.(*eddsabn254.PublicKey).A.Y.Set(&minusOne)
+
+	bad := []struct {
+		keys [3]signature.PublicKey
+		err  error
+	}{
+		// one dealer in two slots
+		{[3]signature.PublicKey{keys[0], keys[1], keys[0]}, errDuplicateDealer},
+		// keys of low order, the neutral point and the point of order 2, sign nothing of value
+		{[3]signature.PublicKey{keys[0], rawKey(0, 1), keys[2]}, errLowOrderKey},
+		{[3]signature.PublicKey{orderTwo, keys[1], keys[2]}, errLowOrderKey},
+		// not a point of the curve
+		{[3]signature.PublicKey{keys[0], keys[1], rawKey(1, 1)}, errKeyNotOnCurve},
+	}
+	for _, test := range bad {
+		var w dealerKeysCircuit
+		err := assignDealerKeys(&w.PublicKeys, test.keys)
+		assert.True(errors.Is(err, test.err), err)
+		assert.SolvingFailed(r1cs, witness(test.keys))
+	}
+}
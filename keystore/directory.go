@@ -0,0 +1,104 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+var (
+	errDuplicateDealer = errors.New("dealer is listed twice")
+	errDuplicateKey    = errors.New("public key is shared by two dealers")
+	errUnknownDealer   = errors.New("dealer not found in the directory")
+)
+
+// Dealer is a counterparty that can be asked for a quote
+type Dealer struct {
+	Name      string
+	PublicKey signature.PublicKey
+}
+
+// dealerJSON is how a dealer is written in a directory: {"name": "HSBC", "publicKey": "hex"}
+type dealerJSON struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"publicKey"`
+}
+
+// MarshalJSON writes the public key as hex
+func (d Dealer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dealerJSON{Name: d.Name, PublicKey: PublicKeyHex(d.PublicKey)})
+}
+
+// UnmarshalJSON reads a dealer written by MarshalJSON
+func (d *Dealer) UnmarshalJSON(buf []byte) error {
+	var dealer dealerJSON
+	if err := json.Unmarshal(buf, &dealer); err != nil {
+		return err
+	}
+	pk, err := ParsePublicKeyHex(dealer.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dealer.Name, err)
+	}
+	d.Name, d.PublicKey = dealer.Name, pk
+	return nil
+}
+
+// Directory lists the dealers an initiator sends RFQs to, in the order of the circuit counterparties
+type Directory struct {
+	Dealers []Dealer `json:"dealers"`
+}
+
+// ReadDirectory reads a JSON directory of dealers and checks names and keys are unique
+func ReadDirectory(r io.Reader) (*Directory, error) {
+	var directory Directory
+	if err := json.NewDecoder(r).Decode(&directory); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, dealer := range directory.Dealers {
+		key := PublicKeyHex(dealer.PublicKey)
+		if names[dealer.Name] {
+			return nil, fmt.Errorf("%s: %w", dealer.Name, errDuplicateDealer)
+		}
+		if keys[key] {
+			return nil, fmt.Errorf("%s: %w", dealer.Name, errDuplicateKey)
+		}
+		names[dealer.Name], keys[key] = true, true
+	}
+	return &directory, nil
+}
+
+// LoadDirectory reads a directory file written by Directory.Save
+func LoadDirectory(path string) (*Directory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadDirectory(f)
+}
+
+// Save writes the directory as JSON
+func (d *Directory) Save(path string) error {
+	buf, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(buf, '\n'), 0644)
+}
+
+// Lookup returns the public key of a dealer
+func (d *Directory) Lookup(name string) (signature.PublicKey, error) {
+	for _, dealer := range d.Dealers {
+		if dealer.Name == name {
+			return dealer.PublicKey, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, errUnknownDealer)
+}
@@ -0,0 +1,180 @@
+// Package keystore generates, stores and shares the EdDSA BN254 keys dealers sign their quotes with.
+//
+// Private keys are stored encrypted with a passphrase: the encryption key is derived with scrypt
+// and the key bytes are sealed with AES-256-GCM. Public keys are shared as hex, alone or in a
+// JSON directory of dealers.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	version = 1
+	curve   = "bn254"
+
+	// scrypt parameters recommended for interactive logins
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltSize     = 32
+)
+
+var (
+	errVersion    = errors.New("unsupported keystore version")
+	errCurve      = errors.New("keystore key is not on bn254")
+	errPassphrase = errors.New("wrong passphrase or corrupted keystore")
+)
+
+// Generate returns a new dealer key from crypto/rand
+func Generate() (signature.Signer, error) {
+	return signature.EDDSA_BN254.New(rand.Reader)
+}
+
+// EncryptedKey is the keystore format of a private key
+type EncryptedKey struct {
+	Version    int    `json:"version"`
+	Curve      string `json:"curve"`
+	PublicKey  string `json:"publicKey"` // hex, as PublicKeyHex
+	Salt       string `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"` // AES-GCM sealed private key bytes, authenticating the public key
+}
+
+// Encrypt encrypts a private key with a passphrase
+func Encrypt(key signature.Signer, passphrase []byte) (*EncryptedKey, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	public := key.Public().Bytes()
+
+	return &EncryptedKey{
+		Version:    version,
+		Curve:      curve,
+		PublicKey:  hex.EncodeToString(public),
+		Salt:       hex.EncodeToString(salt),
+		N:          scryptN,
+		R:          scryptR,
+		P:          scryptP,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: hex.EncodeToString(aead.Seal(nil, nonce, key.Bytes(), public)),
+	}, nil
+}
+
+// Decrypt returns the private key encrypted with passphrase
+func (k *EncryptedKey) Decrypt(passphrase []byte) (signature.Signer, error) {
+	if k.Version != version {
+		return nil, errVersion
+	}
+	if k.Curve != curve {
+		return nil, errCurve
+	}
+	var salt, nonce, ciphertext, public []byte
+	for _, field := range []struct {
+		dst *[]byte
+		src string
+	}{{&salt, k.Salt}, {&nonce, k.Nonce}, {&ciphertext, k.Ciphertext}, {&public, k.PublicKey}} {
+		decoded, err := hex.DecodeString(field.src)
+		if err != nil {
+			return nil, err
+		}
+		*field.dst = decoded
+	}
+
+	aead, err := newAEAD(passphrase, salt, k.N, k.R, k.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errPassphrase
+	}
+	plain, err := aead.Open(nil, nonce, ciphertext, public)
+	if err != nil {
+		return nil, errPassphrase
+	}
+
+	var key eddsa.PrivateKey
+	if _, err := key.SetBytes(plain); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func newAEAD(passphrase, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	derived, err := scrypt.Key(passphrase, salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Save writes a private key encrypted with passphrase to a file only readable by its owner
+func Save(path string, key signature.Signer, passphrase []byte) error {
+	encrypted, err := Encrypt(key, passphrase)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(encrypted, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(buf, '\n'), 0600)
+}
+
+// Load reads a private key written by Save
+func Load(path string, passphrase []byte) (signature.Signer, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var encrypted EncryptedKey
+	if err := json.Unmarshal(buf, &encrypted); err != nil {
+		return nil, err
+	}
+	return encrypted.Decrypt(passphrase)
+}
+
+// PublicKeyHex returns the compressed public key as hex
+func PublicKeyHex(pk signature.PublicKey) string {
+	return hex.EncodeToString(pk.Bytes())
+}
+
+// ParsePublicKeyHex reads a public key written by PublicKeyHex and checks it is on the curve
+func ParsePublicKeyHex(s string) (signature.PublicKey, error) {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var pk eddsa.PublicKey
+	if _, err := pk.SetBytes(buf); err != nil {
+		return nil, err
+	}
+	return &pk, nil
+}
@@ -0,0 +1,115 @@
+package keystore
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/hash"
+)
+
+func TestKeystore(t *testing.T) {
+	key, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key.Bytes(), other.Bytes()) {
+		t.Fatal("generated keys should be random")
+	}
+
+	path := filepath.Join(t.TempDir(), "dealer.key")
+	passphrase := []byte("correct horse battery staple")
+	if err := Save(path, key, passphrase); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the loaded key signs for the same public key
+	msg := []byte{42}
+	sig, err := loaded.Sign(msg, hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := key.Public().Verify(sig, msg, hash.MIMC_BN254.New("seed")); !ok || err != nil {
+		t.Fatal("the loaded key doesn't match the saved key", err)
+	}
+
+	if _, err := Load(path, []byte("wrong")); err == nil {
+		t.Fatal("a wrong passphrase should fail")
+	}
+
+	// the public key is authenticated
+	encrypted, err := Encrypt(key, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted.PublicKey = PublicKeyHex(other.Public())
+	if _, err := encrypted.Decrypt(passphrase); err == nil {
+		t.Fatal("a replaced public key should fail")
+	}
+}
+
+func TestPublicKeyHex(t *testing.T) {
+	key, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := ParsePublicKeyHex(PublicKeyHex(key.Public()))
+	if err != nil || !pk.Equal(key.Public()) {
+		t.Fatal("public key hex round trip failed", err)
+	}
+	for _, s := range []string{"", "zz", strings.Repeat("ff", 32)} {
+		if _, err := ParsePublicKeyHex(s); err == nil {
+			t.Errorf("%q should not be a public key", s)
+		}
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	var directory Directory
+	for _, name := range []string{"HSBC", "RBC", "TD"} {
+		key, err := Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		directory.Dealers = append(directory.Dealers, Dealer{Name: name, PublicKey: key.Public()})
+	}
+
+	path := filepath.Join(t.TempDir(), "dealers.json")
+	if err := directory.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDirectory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Dealers) != 3 {
+		t.Fatal("wrong number of dealers", len(loaded.Dealers))
+	}
+	pk, err := loaded.Lookup("RBC")
+	if err != nil || !pk.Equal(directory.Dealers[1].PublicKey) {
+		t.Fatal("wrong dealer key", err)
+	}
+	if _, err := loaded.Lookup("BMO"); err == nil {
+		t.Fatal("unknown dealer should fail")
+	}
+
+	key := PublicKeyHex(directory.Dealers[0].PublicKey)
+	for _, s := range []string{
+		`{"dealers": [{"name": "HSBC", "publicKey": "` + key + `"}, {"name": "HSBC", "publicKey": "` + PublicKeyHex(directory.Dealers[1].PublicKey) + `"}]}`,
+		`{"dealers": [{"name": "HSBC", "publicKey": "` + key + `"}, {"name": "RBC", "publicKey": "` + key + `"}]}`,
+		`{"dealers": [{"name": "HSBC", "publicKey": "not hex"}]}`,
+	} {
+		if _, err := ReadDirectory(strings.NewReader(s)); err == nil {
+			t.Errorf("%s should fail", s)
+		}
+	}
+}
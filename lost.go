@@ -0,0 +1,54 @@
+package financial
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// lostQuoteCircuit is the proof the initiator gives each dealer whose quote wasn't accepted: another
+// dealer's quote sealed in the same RFQ, signed for the same bond, is strictly better. The better quote,
+// its dealer and its slot stay private, so the losing dealer learns neither the winning price nor the winner.
+type lostQuoteCircuit struct {
+	DealerKey         PublicKey            `gnark:",public"`  // key of the losing dealer
+	DealerCommitment  frontend.Variable    `gnark:",public"`  // sealed quote of the losing dealer
+	Bond              frontend.Variable    `gnark:",public"`  // hash of the bond attributes
+	RFQID             frontend.Variable    `gnark:",public"`  // unique to the RFQ
+	QuoteCommitments  [3]frontend.Variable `gnark:",public"`  // sealed quotes of the RFQ, as in bondCircuit
+	DealerQuote       frontend.Variable    `gnark:",private"` // clean price * size, in cents
+	DealerSigned      Signature            `gnark:",private"` // Sign(Bond hash, RFQID, quote) by the losing dealer
+	DealerBlinding    frontend.Variable    `gnark:",private"` // blinding factor of DealerCommitment
+	CompetingKey      PublicKey            `gnark:",private"` // key of the dealer with the better quote
+	CompetingQuote    frontend.Variable    `gnark:",private"` // the better quote
+	CompetingSigned   Signature            `gnark:",private"` // Sign(Bond hash, RFQID, quote) by that dealer
+	CompetingBlinding frontend.Variable    `gnark:",private"` // blinding factor of its commitment
+	CompetingIndex    frontend.Variable    `gnark:",private"` // slot of its commitment in QuoteCommitments
+}
+
+func (circuit *lostQuoteCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	// the quote of the dealer is the one it sealed
+	mustBeSignedQuote(cs, mimc, params, circuit.Bond, circuit.RFQID, circuit.DealerQuote, circuit.DealerSigned, circuit.DealerKey)
+	commitment := quoteCommitment(cs, mimc, circuit.RFQID, circuit.Bond, circuit.DealerQuote, circuit.DealerSigned, circuit.DealerBlinding)
+	cs.AssertIsEqual(circuit.DealerCommitment, commitment)
+
+	// the competing quote is a quote from another dealer, sealed in the RFQ
+	cs.AssertIsEqual(cs.IsZero(circuit.CompetingQuote, curveID), 0)
+	mustBeValidKey(cs, params, circuit.CompetingKey)
+	cs.AssertIsEqual(isSameKey(cs, curveID, circuit.DealerKey, circuit.CompetingKey), 0)
+	mustBeSignedQuote(cs, mimc, params, circuit.Bond, circuit.RFQID, circuit.CompetingQuote, circuit.CompetingSigned, circuit.CompetingKey)
+	commitment = quoteCommitment(cs, mimc, circuit.RFQID, circuit.Bond, circuit.CompetingQuote, circuit.CompetingSigned, circuit.CompetingBlinding)
+	cs.AssertIsEqual(selectByIndex(cs, curveID, circuit.CompetingIndex, circuit.QuoteCommitments[:]), commitment)
+
+	// and strictly better: a pass is above any quote, so it never beats one
+	mustBeat(cs, circuit.CompetingQuote, circuit.DealerQuote)
+
+	return nil
+}
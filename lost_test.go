@@ -0,0 +1,90 @@
+package financial
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+// sealedTestQuote is a quote signed and sealed by a dealer, with what it reveals to the initiator
+type sealedTestQuote struct {
+	key        signature.Signer
+	signed     *SignedQuote
+	reveal     *QuoteReveal
+	commitment []byte
+}
+
+func TestLostQuote(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("CA29250NAT24")
+	rfqID := big.NewInt(1)
+
+	seal := func(key signature.Signer, rfqID, quote *big.Int) sealedTestQuote {
+		signed, err := NewLocalSigner(key, nil).SignQuote(f.bond, rfqID, quote)
+		assert.NoError(err)
+		reveal, commitment, err := SealQuote(key.Public(), rfqID, f.bondHash, signed)
+		assert.NoError(err)
+		return sealedTestQuote{key, signed, reveal, commitment}
+	}
+	quotes := [3]sealedTestQuote{
+		seal(f.keys[0], rfqID, big.NewInt(52011500)),
+		seal(f.keys[1], rfqID, big.NewInt(50946500)),
+		seal(f.keys[2], rfqID, big.NewInt(51000000)),
+	}
+	commitments := [3][]byte{quotes[0].commitment, quotes[1].commitment, quotes[2].commitment}
+
+	var circuit lostQuoteCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(dealer, competing sealedTestQuote, index int, commitments [3][]byte) *lostQuoteCircuit {
+		var w lostQuoteCircuit
+		assignPublicKey(&w.DealerKey, dealer.key.Public().Bytes())
+		w.DealerCommitment.Assign(dealer.commitment)
+		w.Bond.Assign(f.bondHash)
+		w.RFQID.Assign(rfqID)
+		for i := range commitments {
+			w.QuoteCommitments[i].Assign(commitments[i])
+		}
+		w.DealerQuote.Assign(dealer.signed.Quote)
+		assert.NoError(assignSignature(&w.DealerSigned, dealer.signed.BondSignature))
+		w.DealerBlinding.Assign(dealer.reveal.Blinding)
+		assignPublicKey(&w.CompetingKey, competing.key.Public().Bytes())
+		w.CompetingQuote.Assign(competing.signed.Quote)
+		assert.NoError(assignSignature(&w.CompetingSigned, competing.signed.BondSignature))
+		w.CompetingBlinding.Assign(competing.reveal.Blinding)
+		w.CompetingIndex.Assign(index)
+		return &w
+	}
+
+	// both losing dealers are shown a better quote, whichever it is
+	assert.SolvingSucceeded(r1cs, witness(quotes[0], quotes[1], 1, commitments))
+	assert.SolvingSucceeded(r1cs, witness(quotes[0], quotes[2], 2, commitments))
+	assert.SolvingSucceeded(r1cs, witness(quotes[2], quotes[1], 1, commitments))
+
+	// the winner can't be told it lost, a worse quote doesn't beat it
+	assert.SolvingFailed(r1cs, witness(quotes[1], quotes[2], 2, commitments))
+	assert.SolvingFailed(r1cs, witness(quotes[2], quotes[0], 0, commitments))
+
+	// nor can an equal quote, a pass, a quote sealed in another RFQ or not at its slot
+	tie := seal(f.keys[2], rfqID, big.NewInt(52011500))
+	assert.SolvingFailed(r1cs, witness(quotes[0], tie, 2, [3][]byte{quotes[0].commitment, quotes[1].commitment, tie.commitment}))
+	pass := seal(f.keys[1], rfqID, PassQuote)
+	assert.SolvingFailed(r1cs, witness(quotes[0], pass, 1, [3][]byte{quotes[0].commitment, pass.commitment, quotes[2].commitment}))
+	elsewhere := seal(f.keys[1], big.NewInt(2), big.NewInt(40000000))
+	assert.SolvingFailed(r1cs, witness(quotes[0], elsewhere, 1, commitments))
+	assert.SolvingFailed(r1cs, witness(quotes[0], quotes[1], 2, commitments))
+
+	// the better quote is from another dealer
+	undercut := seal(f.keys[0], rfqID, big.NewInt(40000000))
+	undercutCommitments := [3][]byte{quotes[0].commitment, undercut.commitment, quotes[2].commitment}
+	assert.SolvingFailed(r1cs, witness(quotes[0], undercut, 1, undercutCommitments))
+	assert.SolvingSucceeded(r1cs, witness(quotes[2], undercut, 1, undercutCommitments))
+}
@@ -0,0 +1,123 @@
+package financial
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+var errNullifierUsed = errors.New("quote already used in an accepted proof")
+
+// QuoteNullifier is MiMC(RFQ ID, bond quote signature), public output of bondCircuit for each dealer quote.
+// The signature is canonical, so a quote has a single nullifier in a RFQ.
+func QuoteNullifier(rfqID *big.Int, bondSignature []byte) ([]byte, error) {
+	rx, ry, s1, s2, err := parseSignature(ecc.BN254, bondSignature)
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(rfqID, new(big.Int).SetBytes(rx), new(big.Int).SetBytes(ry),
+		new(big.Int).SetBytes(s1), new(big.Int).SetBytes(s2)), nil
+}
+
+// NullifierRegistry records the nullifiers of the accepted proofs, the Go counterpart of the
+// contract written by ExportNullifierRegistry
+type NullifierRegistry struct {
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+// NewNullifierRegistry returns an empty registry
+func NewNullifierRegistry() *NullifierRegistry {
+	return &NullifierRegistry{used: make(map[string]bool)}
+}
+
+// nullifierKey reads a nullifier as a field element, so leading zeros don't make another key
+func nullifierKey(nullifier []byte) string {
+	return new(big.Int).SetBytes(nullifier).String()
+}
+
+// Nullify records the nullifiers of a proof, or none of them if one is already used
+func (r *NullifierRegistry) Nullify(nullifiers ...[]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, nullifier := range nullifiers {
+		key := nullifierKey(nullifier)
+		if r.used[key] || seen[key] {
+			return errNullifierUsed
+		}
+		seen[key] = true
+	}
+	for key := range seen {
+		r.used[key] = true
+	}
+	return nil
+}
+
+// Used reports whether the nullifier belongs to an accepted proof
+func (r *NullifierRegistry) Used(nullifier []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.used[nullifierKey(nullifier)]
+}
+
+var nullifierRegistryTemplate = template.Must(template.New("registry").Parse(`// SPDX-License-Identifier: AML
+pragma solidity ^0.8.0;
+
+import "./{{.Verifier}}";
+
+// NullifierRegistry records the nullifiers of the accepted proofs, so no signed quote is used twice
+contract NullifierRegistry {
+    mapping(uint256 => bool) public nullified;
+
+    event Nullified(uint256 nullifier);
+
+    function _nullify(uint256[] memory nullifiers) internal {
+        for (uint256 i = 0; i < nullifiers.length; i++) {
+            require(!nullified[nullifiers[i]], "nullifier already used");
+            nullified[nullifiers[i]] = true;
+            emit Nullified(nullifiers[i]);
+        }
+    }
+}
+
+// RFQRegistry accepts a valid proof only if none of its quotes was used in an accepted proof before
+contract RFQRegistry is Verifier, NullifierRegistry {
+    function submitProof(
+        uint256[2] memory a,
+        uint256[2][2] memory b,
+        uint256[2] memory c,
+        uint256[{{.Inputs}}] memory input
+    ) public returns (bool) {
+        require(verifyProof(a, b, c, input), "invalid proof");
+        uint256[] memory nullifiers = new uint256[]({{len .Nullifiers}});
+{{- range $i, $input := .Nullifiers}}
+        nullifiers[{{$i}}] = input[{{$input}}];
+{{- end}}
+        _nullify(nullifiers);
+        return true;
+    }
+}
+`))
+
+// ExportNullifierRegistry writes the Solidity registry accepting proofs checked by the Verifier contract
+// in the file verifier, with that many public inputs of which the nullifiers are at the given indexes
+func ExportNullifierRegistry(w io.Writer, verifier string, inputs int, nullifiers []int) error {
+	return nullifierRegistryTemplate.Execute(w, struct {
+		Verifier   string
+		Inputs     int
+		Nullifiers []int
+	}{verifier, inputs, nullifiers})
+}
+
+// quoteNullifier is the nullifier of a bond quote signature in the RFQ, as computed by QuoteNullifier
+func quoteNullifier(cs *frontend.ConstraintSystem, hash mimc.MiMC, rfqID frontend.Variable, signed Signature) frontend.Variable {
+	return hash.Hash(cs, rfqID, signed.R.X, signed.R.Y, signed.S1, signed.S2)
+}
@@ -0,0 +1,128 @@
+package financial
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+type nullifierCircuit struct {
+	PublicKey PublicKey         `gnark:",public"`
+	Bond      frontend.Variable `gnark:",public"`
+	RFQID     frontend.Variable `gnark:",public"`
+	Nullifier frontend.Variable `gnark:",public"`
+	Quote     frontend.Variable `gnark:",private"`
+	Signed    Signature         `gnark:",private"`
+}
+
+func (circuit *nullifierCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	hash, err := mimc.NewMiMC("seed", curveID)
+	if err != nil {
+		return err
+	}
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mustBeCanonical(cs, circuit.Signed)
+	circuit.PublicKey.Curve = params
+	eddsa.Verify(cs, circuit.Signed, hash.Hash(cs, circuit.Bond, circuit.RFQID, circuit.Quote), circuit.PublicKey)
+	cs.AssertIsEqual(circuit.Nullifier, quoteNullifier(cs, hash, circuit.RFQID, circuit.Signed))
+	return nil
+}
+
+func TestNullifiers(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	assert.NoError(err)
+	bond := lookupTestBond(master, "CA29250NAT24")
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+	key, err := keystore.Generate()
+	assert.NoError(err)
+	sign := func(rfqID *big.Int) *SignedQuote {
+		signed, err := NewLocalSigner(key, nil).SignQuote(bond, rfqID, big.NewInt(50946500))
+		assert.NoError(err)
+		return signed
+	}
+
+	var circuit nullifierCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(r1cs)
+	assert.NoError(err)
+
+	witness := func(signed *SignedQuote, rfqID *big.Int, nullifier []byte) *nullifierCircuit {
+		var w nullifierCircuit
+		assignPublicKey(&w.PublicKey, key.Public().Bytes())
+		w.Bond.Assign(bondHash)
+		w.RFQID.Assign(rfqID)
+		w.Nullifier.Assign(nullifier)
+		w.Quote.Assign(signed.Quote)
+		assert.NoError(assignSignature(&w.Signed, signed.BondSignature))
+		return &w
+	}
+	prove := func(signed *SignedQuote, rfqID *big.Int) []byte {
+		nullifier, err := QuoteNullifier(rfqID, signed.BondSignature)
+		assert.NoError(err)
+		proof, err := groth16.Prove(r1cs, pk, witness(signed, rfqID, nullifier))
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, witness(signed, rfqID, nullifier)))
+		return nullifier
+	}
+
+	// the same signed quote in two proofs of a RFQ has the same nullifier, accepted once
+	registry := NewNullifierRegistry()
+	rfqID := big.NewInt(7)
+	signed := sign(rfqID)
+	first, second := prove(signed, rfqID), prove(signed, rfqID)
+	assert.Equal(first, second)
+	assert.NoError(registry.Nullify(first))
+	assert.True(registry.Used(first))
+	assert.True(errors.Is(registry.Nullify(second), errNullifierUsed))
+
+	// the signed quote can't be replayed under another RFQ ID for a fresh nullifier
+	replayed, err := QuoteNullifier(big.NewInt(8), signed.BondSignature)
+	assert.NoError(err)
+	assert.SolvingFailed(r1cs, witness(signed, big.NewInt(8), replayed))
+
+	// a proof reusing one quote among quotes signed for a new RFQ is rejected whole
+	fresh := prove(sign(big.NewInt(8)), big.NewInt(8))
+	assert.True(errors.Is(registry.Nullify(fresh, second), errNullifierUsed))
+	assert.False(registry.Used(fresh))
+	assert.True(errors.Is(registry.Nullify(fresh, fresh), errNullifierUsed))
+	assert.NoError(registry.Nullify(fresh))
+
+	// the prover can't pick the nullifier
+	assert.SolvingFailed(r1cs, witness(signed, rfqID, replayed))
+	assert.SolvingFailed(r1cs, witness(signed, rfqID, []byte{1}))
+	assert.SolvingSucceeded(r1cs, witness(signed, rfqID, first))
+
+	// the contract reads the nullifiers from the public inputs of the proof
+	var sol bytes.Buffer
+	assert.NoError(ExportNullifierRegistry(&sol, "bond.sol", 49, []int{46, 47, 48}))
+	for _, line := range []string{
+		`import "./bond.sol";`,
+		"contract RFQRegistry is Verifier, NullifierRegistry {",
+		"uint256[49] memory input",
+		"new uint256[](3);",
+		"nullifiers[0] = input[46];",
+		"nullifiers[2] = input[48];",
+	} {
+		assert.True(strings.Contains(sol.String(), line), line)
+	}
+}
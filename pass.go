@@ -0,0 +1,40 @@
+package financial
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// PassQuote is the quote a dealer signs to decline a RFQ. It is above any quote a signer accepts,
+// so a pass never wins but its signature still proves the dealer was asked and answered.
+var PassQuote = new(big.Int).Set(maxQuote)
+
+// Pass signs the pass response of a dealer for bond in the RFQ rfqID
+func Pass(signer QuoteSigner, bond *Bond, rfqID *big.Int) (*SignedQuote, error) {
+	return signer.SignQuote(bond, rfqID, PassQuote)
+}
+
+// IsPass reports whether the dealer declined to quote
+func (q *SignedQuote) IsPass() bool {
+	return q.Quote.Cmp(PassQuote) == 0
+}
+
+// mustBeBestQuote asserts accepted is a quote no larger than any of the quotes and not a pass,
+// and returns the number of quotes that aren't passes
+func mustBeBestQuote(cs *frontend.ConstraintSystem, curveID ecc.ID, accepted frontend.Variable, quotes [3]frontend.Variable) frontend.Variable {
+	pass := cs.Constant(PassQuote)
+
+	isPass := cs.IsZero(cs.Sub(accepted, pass), curveID)
+	cs.AssertIsEqual(isPass, 0)
+
+	count := cs.Constant(0)
+	for i := range quotes {
+		// passes are above any quote, so they never lower the minimum
+		cs.AssertIsLessOrEqual(accepted, quotes[i])
+		isPass := cs.IsZero(cs.Sub(quotes[i], pass), curveID)
+		count = cs.Add(count, cs.Sub(1, isPass))
+	}
+	return count
+}
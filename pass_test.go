@@ -0,0 +1,99 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+type bestQuoteCircuit struct {
+	PublicKeys [3]PublicKey         `gnark:",public"`
+	Accepted   frontend.Variable    `gnark:",public"`
+	QuoteCount frontend.Variable    `gnark:",public"`
+	Quotes     [3]frontend.Variable `gnark:",private"`
+	Signatures [3]Signature         `gnark:",private"`
+}
+
+func (circuit *bestQuoteCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	for i := range circuit.Quotes {
+		circuit.PublicKeys[i].Curve = params
+		eddsa.Verify(cs, circuit.Signatures[i], circuit.Quotes[i], circuit.PublicKeys[i])
+	}
+	count := mustBeBestQuote(cs, curveID, circuit.Accepted, circuit.Quotes)
+	cs.AssertIsEqual(circuit.QuoteCount, count)
+	return nil
+}
+
+func TestPass(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	assert.NoError(err)
+	bond := lookupTestBond(master, "CA29250NAT24")
+	rfqID := big.NewInt(1)
+
+	// a dealer can decline a bond its policy doesn't let it quote
+	key, err := keystore.Generate()
+	assert.NoError(err)
+	signer := NewLocalSigner(key, &SigningPolicy{AllowedIsins: []string{"US46625HKC33"}})
+	_, err = signer.SignQuote(bond, rfqID, big.NewInt(50946500))
+	assert.True(errors.Is(err, errIsinNotAllowed), err)
+	pass, err := Pass(signer, bond, rfqID)
+	assert.NoError(err)
+	assert.True(pass.IsPass())
+	ok, err := key.Public().Verify(pass.Signature, PassQuote.Bytes(), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.True(ok)
+
+	// but can't sign anything above a quote that isn't a pass
+	_, err = NewLocalSigner(key, nil).SignQuote(bond, rfqID, new(big.Int).Add(PassQuote, big.NewInt(1)))
+	assert.True(errors.Is(err, errMalformedQuote), err)
+
+	var circuit bestQuoteCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(accepted *big.Int, count int, quotes ...*big.Int) *bestQuoteCircuit {
+		var w bestQuoteCircuit
+		w.Accepted.Assign(accepted)
+		w.QuoteCount.Assign(count)
+		for i, quote := range quotes {
+			key, err := keystore.Generate()
+			assert.NoError(err)
+			signed, err := NewLocalSigner(key, nil).SignQuote(bond, rfqID, quote)
+			assert.NoError(err)
+			assignPublicKey(&w.PublicKeys[i], key.Public().Bytes())
+			assignSignature(&w.Signatures[i], signed.Signature)
+			w.Quotes[i].Assign(quote)
+		}
+		return &w
+	}
+	low, high := big.NewInt(50946500), big.NewInt(52011500)
+
+	// the passing dealer is proven asked but left out of the minimum and the count
+	assert.SolvingSucceeded(r1cs, witness(low, 2, high, PassQuote, low))
+	assert.SolvingSucceeded(r1cs, witness(low, 1, PassQuote, PassQuote, low))
+	assert.SolvingSucceeded(r1cs, witness(low, 3, high, low, low))
+
+	// a pass isn't a quote
+	assert.SolvingFailed(r1cs, witness(low, 3, high, PassQuote, low))
+	assert.SolvingFailed(r1cs, witness(PassQuote, 0, PassQuote, PassQuote, PassQuote))
+
+	// and the accepted quote is still the best one
+	assert.SolvingFailed(r1cs, witness(high, 2, high, PassQuote, low))
+}
@@ -0,0 +1,166 @@
+package financial
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+const (
+	// basketDepth is the depth of the Merkle tree of a basket, fixed by the size of portfolioCircuit
+	basketDepth = 2
+	// basketSize is the most bonds a list RFQ can hold, the leaves of the tree.
+	// Smaller baskets are padded with empty leaves, zero, after their bonds.
+	basketSize = 1 << basketDepth
+)
+
+var errBasketSize = fmt.Errorf("a basket lists 1 to %d bonds", basketSize)
+
+// BasketRoot is the Merkle root of the hashes of the bonds of a basket, in order:
+// the leaves are the bond hashes padded with zeros to basketSize, and each node is MiMC(left, right).
+// A bond hash can't be zero, the empty leaf.
+func BasketRoot(bondHashes [][]byte) ([]byte, error) {
+	if len(bondHashes) == 0 || len(bondHashes) > basketSize {
+		return nil, errBasketSize
+	}
+	level := make([]*big.Int, basketSize)
+	for i := range level {
+		level[i] = new(big.Int)
+	}
+	for i, h := range bondHashes {
+		level[i].SetBytes(h)
+		if level[i].Sign() == 0 {
+			return nil, errMalformedBond
+		}
+	}
+	for len(level) > 1 {
+		next := make([]*big.Int, len(level)/2)
+		for i := range next {
+			next[i] = new(big.Int).SetBytes(hashFields(level[2*i], level[2*i+1]))
+		}
+		level = next
+	}
+	root := make([]byte, 32)
+	level[0].FillBytes(root)
+	return root, nil
+}
+
+// BasketQuote is a dealer quote for a whole basket: a quote for each bond, in basket order, and their total
+type BasketQuote struct {
+	Prices    [basketSize]*big.Int // clean price * size in cents, for each bond, zero for the empty leaves
+	Total     *big.Int             // sum of Prices, the quote ranked in the RFQ
+	Signature []byte               // Sign(MiMC(basket root, RFQ ID, prices, total))
+}
+
+// message is the hash signed by the dealer in the RFQ with that ID, checked in portfolioCircuit
+func (q *BasketQuote) message(root []byte, rfqID *big.Int) []byte {
+	elements := []*big.Int{new(big.Int).SetBytes(root), rfqID}
+	elements = append(elements, q.Prices[:]...)
+	return hashFields(append(elements, q.Total)...)
+}
+
+// SignBasketQuote signs the quotes of the bonds of the basket with that root, one for each bond in order, and their total,
+// in the RFQ with that ID
+func SignBasketQuote(key signature.Signer, root []byte, rfqID *big.Int, prices []*big.Int) (*BasketQuote, error) {
+	if len(prices) == 0 || len(prices) > basketSize {
+		return nil, errBasketSize
+	}
+	q := &BasketQuote{Total: new(big.Int)}
+	for i := range q.Prices {
+		q.Prices[i] = new(big.Int)
+	}
+	for i, price := range prices {
+		if price.Sign() <= 0 || price.Cmp(maxQuote) >= 0 {
+			return nil, errMalformedQuote
+		}
+		q.Prices[i].Set(price)
+		q.Total.Add(q.Total, price)
+	}
+	sig, err := key.Sign(q.message(root, rfqID), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	q.Signature = sig
+	return q, nil
+}
+
+// Verify checks the quote is signed by the dealer for the basket in the RFQ with that ID
+func (q *BasketQuote) Verify(dealer signature.PublicKey, root []byte, rfqID *big.Int) bool {
+	return verifySignature(dealer, q.Signature, q.message(root, rfqID))
+}
+
+// portfolioCircuit is the list RFQ variant, where dealers quote a basket of bonds together.
+// It proves the selected dealer quoted the smallest total for the basket, and that the total of each
+// dealer is the sum of its quotes for the bonds. The bonds are public only through the Merkle root of
+// their hashes. A basket holds up to basketSize bonds: dealers quote every listed bond and nothing on the
+// empty leaves.
+type portfolioCircuit struct {
+	Basket            frontend.Variable                `gnark:",public"`  // BasketRoot of the bond hashes
+	RFQID             frontend.Variable                `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	PublicKeyCpts     [3]PublicKey                     `gnark:",public"`  // counterparties asked for a quote
+	AcceptedTotal     frontend.Variable                `gnark:",public"`  // total quoted by the selected dealer
+	BondHashes        [basketSize]frontend.Variable    `gnark:",private"` // bonds of the basket, in order, then zeros
+	Prices            [3][basketSize]frontend.Variable `gnark:",private"` // quote of each dealer for each bond
+	Totals            [3]frontend.Variable             `gnark:",private"` // total quoted by each dealer
+	BasketQuoteSigned [3]Signature                     `gnark:",private"` // Sign(Basket, RFQID, prices, total)
+	Selected          frontend.Variable                `gnark:",private"` // slot of the selected dealer
+}
+
+func (circuit *portfolioCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	cs.AssertIsEqual(circuit.Basket, basketRoot(cs, mimc, circuit.BondHashes))
+	cs.AssertIsEqual(cs.IsZero(circuit.BondHashes[0], curveID), 0)
+	mustBeDistinctKeys(cs, curveID, circuit.PublicKeyCpts)
+
+	for i := range circuit.Prices {
+		// the total is consistent with the quote of each bond, and there is a quote for a leaf only if it lists a bond
+		total := cs.Constant(0)
+		for j, price := range circuit.Prices[i] {
+			cs.AssertIsEqual(cs.IsZero(price, curveID), cs.IsZero(circuit.BondHashes[j], curveID))
+			total = cs.Add(total, price)
+		}
+		cs.AssertIsEqual(circuit.Totals[i], total)
+
+		// and both are signed for the basket
+		message := []frontend.Variable{circuit.Basket, circuit.RFQID}
+		message = append(message, circuit.Prices[i][:]...)
+		message = append(message, circuit.Totals[i])
+		circuit.PublicKeyCpts[i].Curve = params
+		mustBeCanonical(cs, circuit.BasketQuoteSigned[i])
+		eddsa.Verify(cs, circuit.BasketQuoteSigned[i], mimc.Hash(cs, message...), circuit.PublicKeyCpts[i])
+	}
+
+	// the selected total is the smallest one
+	cs.AssertIsEqual(circuit.AcceptedTotal, selectByIndex(cs, curveID, circuit.Selected, circuit.Totals[:]))
+	for i := range circuit.Totals {
+		cs.AssertIsLessOrEqual(circuit.AcceptedTotal, circuit.Totals[i])
+	}
+
+	return nil
+}
+
+// basketRoot is the Merkle root of the bond hashes, as computed by BasketRoot
+func basketRoot(cs *frontend.ConstraintSystem, hash mimc.MiMC, bondHashes [basketSize]frontend.Variable) frontend.Variable {
+	level := bondHashes[:]
+	for len(level) > 1 {
+		next := make([]frontend.Variable, len(level)/2)
+		for i := range next {
+			next[i] = hash.Hash(cs, level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	return level[0]
+}
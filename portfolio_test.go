@@ -0,0 +1,150 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestPortfolio(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	// the basket starts with the bond of the fixture
+	f := newRFQFixture("CA29250NAT24")
+	bondHashes := [][]byte{f.bondHash}
+	for _, isin := range []string{"CA29250NAS41", "US46625HKC33", "US89114QCR74"} {
+		bondHash, err := lookupTestBond(f.master, isin).Hash()
+		assert.NoError(err)
+		bondHashes = append(bondHashes, bondHash)
+	}
+	root, err := BasketRoot(bondHashes)
+	assert.NoError(err)
+	_, err = BasketRoot(nil)
+	assert.True(errors.Is(err, errBasketSize), err)
+	_, err = BasketRoot(append(bondHashes, bondHashes[0]))
+	assert.True(errors.Is(err, errBasketSize), err)
+
+	// a smaller basket is padded with empty leaves
+	short, err := BasketRoot(bondHashes[:3])
+	assert.NoError(err)
+	assert.NotEqual(root, short)
+	_, err = BasketRoot([][]byte{bondHashes[0], {0}, bondHashes[2]})
+	assert.True(errors.Is(err, errMalformedBond), err)
+
+	// the basket is its bonds in order
+	swapped, err := BasketRoot([][]byte{bondHashes[1], bondHashes[0], bondHashes[2], bondHashes[3]})
+	assert.NoError(err)
+	assert.NotEqual(root, swapped)
+
+	rfqID := big.NewInt(1)
+	prices := func(quotes ...int64) []*big.Int {
+		var p []*big.Int
+		for _, q := range quotes {
+			p = append(p, big.NewInt(q))
+		}
+		return p
+	}
+	var quotes [3]*BasketQuote
+	for i, p := range [][]*big.Int{
+		prices(50946500, 143576500, 57250000, 55200000),
+		prices(51000000, 142000000, 57000000, 55000000),
+		prices(50000000, 144000000, 58000000, 56000000),
+	} {
+		quotes[i], err = SignBasketQuote(f.keys[i], root, rfqID, p)
+		assert.NoError(err)
+		assert.True(quotes[i].Verify(f.keys[i].Public(), root, rfqID))
+		assert.False(quotes[i].Verify(f.keys[i].Public(), swapped, rfqID))
+		assert.False(quotes[i].Verify(f.keys[i].Public(), root, big.NewInt(2)))
+	}
+	assert.Equal(big.NewInt(305000000), quotes[1].Total)
+	_, err = SignBasketQuote(f.keys[0], root, rfqID, prices(50946500, 0, 57250000, 55200000))
+	assert.True(errors.Is(err, errMalformedQuote), err)
+	_, err = SignBasketQuote(f.keys[0], root, rfqID, prices(50946500, 143576500, 57250000, 55200000, 1))
+	assert.True(errors.Is(err, errBasketSize), err)
+
+	var circuit portfolioCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(selected int, root []byte, bondHashes [][]byte, quotes [3]*BasketQuote) *portfolioCircuit {
+		var w portfolioCircuit
+		w.Basket.Assign(root)
+		w.RFQID.Assign(rfqID)
+		for i := range w.BondHashes {
+			if i < len(bondHashes) {
+				w.BondHashes[i].Assign(bondHashes[i])
+			} else {
+				w.BondHashes[i].Assign(0)
+			}
+		}
+		f.assignKeys(&w.PublicKeyCpts)
+		for i, quote := range quotes {
+			for j, price := range quote.Prices {
+				w.Prices[i][j].Assign(price)
+			}
+			w.Totals[i].Assign(quote.Total)
+			assert.NoError(assignSignature(&w.BasketQuoteSigned[i], quote.Signature))
+		}
+		w.Selected.Assign(selected)
+		w.AcceptedTotal.Assign(quotes[selected].Total)
+		return &w
+	}
+
+	// the smallest total wins, though another dealer is better on some bonds
+	assert.SolvingSucceeded(r1cs, witness(1, root, bondHashes, quotes))
+	assert.SolvingFailed(r1cs, witness(0, root, bondHashes, quotes))
+	assert.SolvingFailed(r1cs, witness(2, root, bondHashes, quotes))
+
+	// a total that isn't the sum of the quotes of the bonds can't win, even signed
+	inconsistent := &BasketQuote{Prices: quotes[0].Prices, Total: big.NewInt(300000000)}
+	inconsistent.Signature, err = f.keys[0].Sign(inconsistent.message(root, rfqID), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.True(inconsistent.Verify(f.keys[0].Public(), root, rfqID))
+	assert.SolvingFailed(r1cs, witness(0, root, bondHashes, [3]*BasketQuote{inconsistent, quotes[1], quotes[2]}))
+
+	// nor the quotes of another basket
+	bad := witness(1, root, bondHashes, quotes)
+	bad.BondHashes = [basketSize]frontend.Variable{}
+	for i, j := range []int{1, 0, 2, 3} {
+		bad.BondHashes[i].Assign(bondHashes[j])
+	}
+	assert.SolvingFailed(r1cs, bad)
+
+	// nor the quotes signed for another RFQ
+	bad = witness(1, root, bondHashes, quotes)
+	bad.RFQID = frontend.Variable{}
+	bad.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, bad)
+
+	// a basket of three bonds, quoted on its three bonds only
+	var shortQuotes [3]*BasketQuote
+	for i, p := range [][]*big.Int{
+		prices(50946500, 143576500, 57250000),
+		prices(51000000, 142000000, 57000000),
+		prices(50000000, 144000000, 58000000),
+	} {
+		shortQuotes[i], err = SignBasketQuote(f.keys[i], short, rfqID, p)
+		assert.NoError(err)
+	}
+	assert.SolvingSucceeded(r1cs, witness(1, short, bondHashes[:3], shortQuotes))
+	assert.SolvingFailed(r1cs, witness(0, short, bondHashes[:3], shortQuotes))
+
+	// a quote on the empty leaf doesn't fit the basket
+	onEmpty := shortQuotes
+	onEmpty[2], err = SignBasketQuote(f.keys[2], short, rfqID, prices(50000000, 144000000, 58000000, 1))
+	assert.NoError(err)
+	assert.SolvingFailed(r1cs, witness(1, short, bondHashes[:3], onEmpty))
+
+	// nor can a dealer leave a bond out to win with a smaller total
+	partial := shortQuotes
+	partial[0], err = SignBasketQuote(f.keys[0], short, rfqID, prices(50946500, 143576500))
+	assert.NoError(err)
+	assert.SolvingFailed(r1cs, witness(0, short, bondHashes[:3], partial))
+}
@@ -0,0 +1,352 @@
+package financial
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+
+	"bloconuts/v0/keystore"
+	"bloconuts/v0/secid"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+)
+
+// maxQuote bounds the quotes a signer accepts: clean price * size in cents is smaller than 2^64
+var maxQuote = new(big.Int).Lsh(big.NewInt(1), 64)
+
+var (
+	errMalformedQuote  = errors.New("quote must be a non negative integer smaller than 2^64, or a pass")
+	errMalformedBond   = errors.New("bond fields are not a valid bond")
+	errMalformedRFQID  = errors.New("RFQ ID must be a non negative field element")
+	errIsinNotAllowed  = errors.New("isin is not allowed by the signing policy")
+	errNotionalTooHigh = errors.New("bond size is above the maximum notional of the signing policy")
+	errBadSignature    = errors.New("remote signer returned an invalid signature")
+)
+
+// QuoteSigner signs the quotes of a dealer for bondCircuit. Dealers keep their private key
+// in a signer process and hand the initiator a QuoteSigner, never the key.
+type QuoteSigner interface {
+	// Public returns the public key the signatures are checked with
+	Public() signature.PublicKey
+
+	// SignQuote signs quote, the clean price * size in cents, for bond in the RFQ rfqID
+	SignQuote(bond *Bond, rfqID, quote *big.Int) (*SignedQuote, error)
+}
+
+// SignedQuote holds a quote and the signatures bondCircuit checks
+type SignedQuote struct {
+	Quote         *big.Int
+	Signature     []byte // Sign(quote), one of SignatureCpts
+	BondSignature []byte // Sign(MiMC(bond hash, RFQ ID, quote)), one of BondQuoteSignedCpts
+}
+
+// SigningPolicy restricts the quotes a signer signs
+type SigningPolicy struct {
+	AllowedIsins []string // any ISIN if empty
+	MaxNotional  *big.Int // maximum bond size, any size if nil
+}
+
+// check returns an error if the policy doesn't allow signing for the bond with these fields
+func (p *SigningPolicy) check(fields [bondFieldsSize]*big.Int) error {
+	if p == nil {
+		return nil
+	}
+	if len(p.AllowedIsins) > 0 {
+		isin := string(fields[0].Bytes())
+		allowed := false
+		for _, a := range p.AllowedIsins {
+			allowed = allowed || a == isin
+		}
+		if !allowed {
+			return fmt.Errorf("%s: %w", isin, errIsinNotAllowed)
+		}
+	}
+	if p.MaxNotional != nil && fields[1].Cmp(p.MaxNotional) > 0 {
+		return errNotionalTooHigh
+	}
+	return nil
+}
+
+// signQuote signs a well-formed quote for the bond encoded by fields in the RFQ rfqID
+func signQuote(key signature.Signer, policy *SigningPolicy, fields [bondFieldsSize]*big.Int, rfqID, quote *big.Int) (*SignedQuote, error) {
+	pass := quote != nil && quote.Cmp(PassQuote) == 0
+	if quote == nil || quote.Sign() < 0 || (quote.Cmp(maxQuote) >= 0 && !pass) {
+		return nil, errMalformedQuote
+	}
+	if rfqID == nil || rfqID.Sign() < 0 || rfqID.Cmp(fr.Modulus()) >= 0 {
+		return nil, errMalformedRFQID
+	}
+	if err := checkBondFields(fields); err != nil {
+		return nil, err
+	}
+	// declining is always allowed
+	if !pass {
+		if err := policy.check(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	hFunc := hash.MIMC_BN254.New("seed")
+	sig, err := key.Sign(quote.Bytes(), hFunc)
+	if err != nil {
+		return nil, err
+	}
+	hFunc.Reset()
+	bondSig, err := key.Sign(bondQuoteHash(hashFields(fields[:]...), rfqID, quote), hFunc)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedQuote{Quote: new(big.Int).Set(quote), Signature: sig, BondSignature: bondSig}, nil
+}
+
+// checkBondFields returns an error unless fields are the encoding of a bond by Bond.Fields, so they can't
+// alias another bond in a circuit. The terms of bonds with call, put or step schedules are hashes of the
+// schedules, only checked to be field elements.
+func checkBondFields(fields [bondFieldsSize]*big.Int) error {
+	for _, f := range fields {
+		if f == nil || f.Sign() < 0 || f.Cmp(fr.Modulus()) >= 0 {
+			return errMalformedBond
+		}
+	}
+	if err := secid.ValidateISIN(string(fields[0].Bytes())); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedBond, err)
+	}
+	if fields[typeField].Cmp(big.NewInt(int64(StepUp))) > 0 {
+		return errMalformedBond
+	}
+
+	bond := bondFromFields(fields)
+	encoded, err := bond.attributeFields()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMalformedBond, err)
+	}
+	switch bond.Type {
+	case Vanilla, FRN:
+		terms, err := bond.terms()
+		if err != nil {
+			return fmt.Errorf("%w: %v", errMalformedBond, err)
+		}
+		copy(encoded[termsField:], terms[:])
+	default:
+		copy(encoded[termsField:], fields[termsField:])
+	}
+	for i := range fields {
+		if fields[i].Cmp(encoded[i]) != 0 {
+			return errMalformedBond
+		}
+	}
+	return nil
+}
+
+// bondQuoteHash is the message of a quote signed for a bond in a RFQ, checked against BondQuoteSignedCpts.
+// The RFQ ID is signed so the quote can't be used in another RFQ.
+func bondQuoteHash(bondHash []byte, rfqID, quote *big.Int) []byte {
+	return hashFields(new(big.Int).SetBytes(bondHash), rfqID, quote)
+}
+
+// localSigner signs quotes with a key held in memory
+type localSigner struct {
+	key    signature.Signer
+	policy *SigningPolicy
+}
+
+// NewLocalSigner returns a QuoteSigner using key in memory. policy can be nil.
+func NewLocalSigner(key signature.Signer, policy *SigningPolicy) QuoteSigner {
+	return &localSigner{key: key, policy: policy}
+}
+
+func (s *localSigner) Public() signature.PublicKey {
+	return s.key.Public()
+}
+
+func (s *localSigner) SignQuote(bond *Bond, rfqID, quote *big.Int) (*SignedQuote, error) {
+	fields, err := bond.Fields()
+	if err != nil {
+		return nil, err
+	}
+	return signQuote(s.key, s.policy, fields, rfqID, quote)
+}
+
+// quoteRequest is the body of a request to a signer process: the bond fields, the RFQ ID and the quote as decimal strings
+type quoteRequest struct {
+	Bond  [bondFieldsSize]string `json:"bond"`
+	RFQ   string                 `json:"rfq"`
+	Quote string                 `json:"quote"`
+}
+
+type quoteResponse struct {
+	Signature     string `json:"signature"`
+	BondSignature string `json:"bondSignature"`
+}
+
+type publicKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+// QuoteSignerServer is the signer process of a dealer. It serves
+//
+//	GET  /public  {"publicKey": hex}
+//	POST /quote   {"bond": [fields], "rfq": "id", "quote": "cents"} -> {"signature": hex, "bondSignature": hex}
+//
+// and only signs well-formed quotes allowed by Policy.
+type QuoteSignerServer struct {
+	Key    signature.Signer
+	Policy *SigningPolicy
+}
+
+func (s *QuoteSignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/public" && r.Method == http.MethodGet:
+		writeJSON(w, publicKeyResponse{PublicKey: keystore.PublicKeyHex(s.Key.Public())})
+	case r.URL.Path == "/quote" && r.Method == http.MethodPost:
+		var request quoteRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var fields [bondFieldsSize]*big.Int
+		for i, f := range request.Bond {
+			field, ok := new(big.Int).SetString(f, 10)
+			if !ok {
+				http.Error(w, fmt.Sprintf("bond field %d is not a decimal integer", i), http.StatusBadRequest)
+				return
+			}
+			fields[i] = field
+		}
+		rfqID, ok := new(big.Int).SetString(request.RFQ, 10)
+		if !ok {
+			http.Error(w, "RFQ ID is not a decimal integer", http.StatusBadRequest)
+			return
+		}
+		quote, ok := new(big.Int).SetString(request.Quote, 10)
+		if !ok {
+			http.Error(w, "quote is not a decimal integer", http.StatusBadRequest)
+			return
+		}
+		signed, err := signQuote(s.Key, s.Policy, fields, rfqID, quote)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		writeJSON(w, quoteResponse{
+			Signature:     hex.EncodeToString(signed.Signature),
+			BondSignature: hex.EncodeToString(signed.BondSignature),
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// RemoteSigner is a QuoteSigner calling the QuoteSignerServer of a dealer
+type RemoteSigner struct {
+	url    string
+	client *http.Client
+	public signature.PublicKey
+}
+
+// NewRemoteSigner connects to the signer process at url and reads its public key.
+// client can be nil for http.DefaultClient, or UnixSocketClient for a signer on a local socket.
+func NewRemoteSigner(url string, client *http.Client) (*RemoteSigner, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	s := &RemoteSigner{url: url, client: client}
+
+	var response publicKeyResponse
+	if err := s.call(http.MethodGet, "/public", nil, &response); err != nil {
+		return nil, err
+	}
+	public, err := keystore.ParsePublicKeyHex(response.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	s.public = public
+	return s, nil
+}
+
+// UnixSocketClient returns a http client connecting to a signer listening on a unix socket,
+// to be used with any url such as "http://signer"
+func UnixSocketClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+func (s *RemoteSigner) Public() signature.PublicKey {
+	return s.public
+}
+
+// SignQuote asks the signer process to sign and checks the signatures it returns
+func (s *RemoteSigner) SignQuote(bond *Bond, rfqID, quote *big.Int) (*SignedQuote, error) {
+	fields, err := bond.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var request quoteRequest
+	for i, f := range fields {
+		request.Bond[i] = f.String()
+	}
+	request.RFQ = rfqID.String()
+	request.Quote = quote.String()
+
+	var response quoteResponse
+	if err := s.call(http.MethodPost, "/quote", request, &response); err != nil {
+		return nil, err
+	}
+	signed := &SignedQuote{Quote: new(big.Int).Set(quote)}
+	if signed.Signature, err = hex.DecodeString(response.Signature); err != nil {
+		return nil, err
+	}
+	if signed.BondSignature, err = hex.DecodeString(response.BondSignature); err != nil {
+		return nil, err
+	}
+
+	if !verifySignature(s.public, signed.Signature, quote.Bytes()) ||
+		!verifySignature(s.public, signed.BondSignature, bondQuoteHash(hashFields(fields[:]...), rfqID, quote)) {
+		return nil, errBadSignature
+	}
+	return signed, nil
+}
+
+func (s *RemoteSigner) call(method, path string, request, response interface{}) error {
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, s.url+path, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var message bytes.Buffer
+		message.ReadFrom(resp.Body)
+		return fmt.Errorf("signer: %s: %s", resp.Status, bytes.TrimSpace(message.Bytes()))
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
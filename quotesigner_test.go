@@ -0,0 +1,142 @@
+package financial
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+func TestQuoteSigner(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	assert.NoError(err)
+	bond := lookupTestBond(master, "CA29250NAT24")
+	other := lookupTestBond(master, "CA29250NAS41")
+
+	key, err := keystore.Generate()
+	assert.NoError(err)
+	policy := &SigningPolicy{AllowedIsins: []string{bond.Isin}, MaxNotional: big.NewInt(1000000)}
+	rfqID := big.NewInt(1)
+	quote := big.NewInt(50946500)
+
+	// the same signatures as signing with the key directly
+	local := NewLocalSigner(key, policy)
+	signed, err := local.SignQuote(bond, rfqID, quote)
+	assert.NoError(err)
+	expected, err := key.Sign(quote.Bytes(), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.Equal(expected, signed.Signature)
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+	expected, err = key.Sign(hashFields(new(big.Int).SetBytes(bondHash), rfqID, quote), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.Equal(expected, signed.BondSignature)
+
+	// the policy and malformed quotes are refused
+	_, err = local.SignQuote(other, rfqID, quote)
+	assert.True(errors.Is(err, errIsinNotAllowed), err)
+	large := *bond
+	large.Size = "2000000"
+	_, err = local.SignQuote(&large, rfqID, quote)
+	assert.True(errors.Is(err, errNotionalTooHigh), err)
+	_, err = local.SignQuote(bond, rfqID, big.NewInt(-1))
+	assert.True(errors.Is(err, errMalformedQuote), err)
+	_, err = local.SignQuote(bond, rfqID, new(big.Int).Add(maxQuote, big.NewInt(1)))
+	assert.True(errors.Is(err, errMalformedQuote), err)
+	_, err = local.SignQuote(bond, big.NewInt(-1), quote)
+	assert.True(errors.Is(err, errMalformedRFQID), err)
+	_, err = local.SignQuote(bond, fr.Modulus(), quote)
+	assert.True(errors.Is(err, errMalformedRFQID), err)
+	_, err = NewLocalSigner(key, nil).SignQuote(other, rfqID, quote)
+	assert.NoError(err)
+
+	server := &QuoteSignerServer{Key: key, Policy: policy}
+
+	// a signer process over http
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	remote, err := NewRemoteSigner(httpServer.URL, nil)
+	assert.NoError(err)
+	assert.Equal(key.Public().Bytes(), remote.Public().Bytes())
+	remoteSigned, err := remote.SignQuote(bond, rfqID, quote)
+	assert.NoError(err)
+	assert.Equal(signed, remoteSigned)
+	_, err = remote.SignQuote(other, rfqID, quote)
+	assert.Error(err)
+	_, err = remote.SignQuote(&large, rfqID, quote)
+	assert.Error(err)
+
+	// the signer process only signs the fields of a bond, as Bond.Fields encodes them
+	fields, err := bond.Fields()
+	assert.NoError(err)
+	post := func(edit func(*quoteRequest)) int {
+		var request quoteRequest
+		for i, f := range fields {
+			request.Bond[i] = f.String()
+		}
+		request.RFQ = rfqID.String()
+		request.Quote = quote.String()
+		edit(&request)
+		var body bytes.Buffer
+		assert.NoError(json.NewEncoder(&body).Encode(request))
+		response, err := http.Post(httpServer.URL+"/quote", "application/json", &body)
+		assert.NoError(err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+	assert.Equal(http.StatusOK, post(func(*quoteRequest) {}))
+	assert.Equal(http.StatusBadRequest, post(func(r *quoteRequest) { r.Bond[1] = "550000.5" }))
+	assert.Equal(http.StatusBadRequest, post(func(r *quoteRequest) { r.Quote = "" }))
+	assert.Equal(http.StatusBadRequest, post(func(r *quoteRequest) { r.RFQ = "" }))
+	assert.Equal(http.StatusForbidden, post(func(r *quoteRequest) { r.RFQ = fr.Modulus().String() }))
+	aliased := new(big.Int).Add(fields[1], fr.Modulus())
+	assert.Equal(http.StatusForbidden, post(func(r *quoteRequest) { r.Bond[1] = aliased.String() }))
+	huge := new(big.Int).Lsh(big.NewInt(1), 300)
+	assert.Equal(http.StatusForbidden, post(func(r *quoteRequest) { r.Bond[4] = huge.String() }))
+	assert.Equal(http.StatusForbidden, post(func(r *quoteRequest) { r.Bond[8] = "13" }))
+	assert.Equal(http.StatusForbidden, post(func(r *quoteRequest) { r.Bond[2], r.Bond[3] = "0", r.Bond[2] }))
+
+	// every bond of the security master is accepted
+	for _, isin := range []string{"CA29250NAT24", "CA29250NAS41", "US46625HKC33", "US48128GT919", "US89114QCR74"} {
+		fields, err := lookupTestBond(master, isin).Fields()
+		assert.NoError(err)
+		assert.NoError(checkBondFields(fields), isin)
+	}
+	_, err = signQuote(key, nil, [bondFieldsSize]*big.Int{}, rfqID, quote)
+	assert.True(errors.Is(err, errMalformedBond), err)
+
+	// the remote signer checks the signatures it gets back
+	otherKey, err := keystore.Generate()
+	assert.NoError(err)
+	impostor := httptest.NewServer(&QuoteSignerServer{Key: otherKey})
+	defer impostor.Close()
+	remote.url = impostor.URL
+	_, err = remote.SignQuote(bond, rfqID, quote)
+	assert.True(errors.Is(err, errBadSignature), err)
+
+	// a signer process on a local socket
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.NoError(err)
+	socketServer := &http.Server{Handler: server}
+	go socketServer.Serve(listener)
+	defer socketServer.Close()
+	remote, err = NewRemoteSigner("http://signer", UnixSocketClient(socket))
+	assert.NoError(err)
+	remoteSigned, err = remote.SignQuote(bond, rfqID, quote)
+	assert.NoError(err)
+	assert.Equal(signed, remoteSigned)
+}
@@ -0,0 +1,86 @@
+package financial
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+var errNotCommitted = errors.New("dealer has no commitment to acknowledge")
+
+// Receipt is the acknowledgement signed by the initiator for a sealed quote: the quote is the
+// Index-th one received for the bond. A dealer holding a receipt for a quote left out of a proof
+// shows the omission with Omitted.
+type Receipt struct {
+	Bond       []byte // bond hash
+	Index      int
+	Commitment []byte // commitment of the dealer quote
+	Signature  []byte // Sign(MiMC(bond hash, index, commitment)) by the initiator
+}
+
+// message is the hash signed by the initiator, checked in bondCircuit
+func (r *Receipt) message() []byte {
+	return hashFields(new(big.Int).SetBytes(r.Bond), big.NewInt(int64(r.Index)), new(big.Int).SetBytes(r.Commitment))
+}
+
+// IssueReceipt signs the receipt of the index-th commitment received for the bond
+func IssueReceipt(initiator signature.Signer, bondHash []byte, index int, commitment []byte) (*Receipt, error) {
+	r := &Receipt{Bond: bondHash, Index: index, Commitment: commitment}
+	sig, err := initiator.Sign(r.message(), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	r.Signature = sig
+	return r, nil
+}
+
+// Verify checks the receipt is signed by the initiator
+func (r *Receipt) Verify(initiator signature.PublicKey) bool {
+	return verifySignature(initiator, r.Signature, r.message())
+}
+
+// Omitted reports whether a proof for the bond with these public receipt count and quote commitments
+// left out the quote of a valid receipt: its index is past the count, or another quote was used in its place
+func (r *Receipt) Omitted(initiator signature.PublicKey, bondHash []byte, count int, commitments [][]byte) bool {
+	if !bytes.Equal(r.Bond, bondHash) || !r.Verify(initiator) {
+		return false
+	}
+	if r.Index >= count || r.Index >= len(commitments) {
+		return true
+	}
+	return !bytes.Equal(r.Commitment, commitments[r.Index])
+}
+
+// Acknowledge issues the receipt of the commitment of a dealer, numbered in the order commitments arrived
+func (r *SealedBidRFQ) Acknowledge(initiator signature.Signer, dealer signature.PublicKey) (*Receipt, error) {
+	key := keystore.PublicKeyHex(dealer)
+	for i, committed := range r.order {
+		if committed == key {
+			return IssueReceipt(initiator, r.Bond, i, r.commitments[key])
+		}
+	}
+	return nil, errNotCommitted
+}
+
+// mustBeReceived verifies the initiator signed the receipt of each commitment, in order, and the
+// public count of receipts is the number of quotes in the proof
+func mustBeReceived(cs *frontend.ConstraintSystem, hash mimc.MiMC, curve twistededwards.EdCurve, bond frontend.Variable,
+	initiator PublicKey, count frontend.Variable, commitments [3]frontend.Variable, receipts [3]Signature) {
+
+	initiator.Curve = curve
+	for i := range commitments {
+		message := hash.Hash(cs, bond, cs.Constant(i), commitments[i])
+		mustBeCanonical(cs, receipts[i])
+		eddsa.Verify(cs, receipts[i], message, initiator)
+	}
+	cs.AssertIsEqual(count, len(commitments))
+}
@@ -0,0 +1,136 @@
+package financial
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+type receiptCircuit struct {
+	Bond         frontend.Variable    `gnark:",public"`
+	Commitments  [3]frontend.Variable `gnark:",public"`
+	InitiatorKey PublicKey            `gnark:",public"`
+	ReceiptCount frontend.Variable    `gnark:",public"`
+	Receipts     [3]Signature         `gnark:",private"`
+}
+
+func (circuit *receiptCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	hash, err := mimc.NewMiMC("seed", curveID)
+	if err != nil {
+		return err
+	}
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mustBeReceived(cs, hash, params, circuit.Bond, circuit.InitiatorKey, circuit.ReceiptCount, circuit.Commitments, circuit.Receipts)
+	return nil
+}
+
+func TestReceipts(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	assert.NoError(err)
+	bond := lookupTestBond(master, "CA29250NAS41")
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+	initiator, err := keystore.Generate()
+	assert.NoError(err)
+
+	// four dealers quote, in this order
+	var dealers []signature.PublicKey
+	var keys []signature.Signer
+	for i := 0; i < 4; i++ {
+		key, err := keystore.Generate()
+		assert.NoError(err)
+		keys = append(keys, key)
+		dealers = append(dealers, key.Public())
+	}
+	deadline := time.Date(2021, 11, 19, 16, 0, 0, 0, time.UTC)
+	rfq := NewSealedBidRFQ(big.NewInt(1), bondHash, deadline, dealers)
+
+	var commitments [][]byte
+	var receipts []*Receipt
+	for i, key := range keys {
+		signed, err := NewLocalSigner(key, nil).SignQuote(bond, rfq.ID, big.NewInt(int64(153000000+i)))
+		assert.NoError(err)
+		_, commitment, err := SealQuote(key.Public(), rfq.ID, bondHash, signed)
+		assert.NoError(err)
+		assert.NoError(rfq.Commit(key.Public(), commitment, deadline.Add(-time.Hour)))
+		receipt, err := rfq.Acknowledge(initiator, key.Public())
+		assert.NoError(err)
+		assert.Equal(i, receipt.Index)
+		assert.True(receipt.Verify(initiator.Public()))
+		assert.False(receipt.Verify(key.Public()))
+		commitments = append(commitments, commitment)
+		receipts = append(receipts, receipt)
+	}
+	outsider, err := keystore.Generate()
+	assert.NoError(err)
+	_, err = rfq.Acknowledge(initiator, outsider.Public())
+	assert.Error(err)
+
+	// a proof over all the quotes omits none
+	for _, receipt := range receipts {
+		assert.False(receipt.Omitted(initiator.Public(), bondHash, 4, commitments))
+	}
+	// a proof over the first three leaves the last dealer out
+	assert.False(receipts[2].Omitted(initiator.Public(), bondHash, 3, commitments[:3]))
+	assert.True(receipts[3].Omitted(initiator.Public(), bondHash, 3, commitments[:3]))
+	// as does putting another quote in place of one acknowledged
+	assert.True(receipts[1].Omitted(initiator.Public(), bondHash, 3, [][]byte{commitments[0], commitments[3], commitments[2]}))
+	// a receipt the initiator didn't sign or for another bond proves nothing
+	forged := *receipts[3]
+	forged.Signature = receipts[2].Signature
+	assert.False(forged.Omitted(initiator.Public(), bondHash, 3, commitments[:3]))
+	assert.False(receipts[3].Omitted(initiator.Public(), commitments[0], 3, commitments[:3]))
+
+	// the circuit checks the receipts of the quotes it uses
+	var circuit receiptCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	var witness receiptCircuit
+	witness.Bond.Assign(bondHash)
+	assignPublicKey(&witness.InitiatorKey, initiator.Public().Bytes())
+	witness.ReceiptCount.Assign(3)
+	for i := range witness.Commitments {
+		witness.Commitments[i].Assign(commitments[i])
+		assignSignature(&witness.Receipts[i], receipts[i].Signature)
+	}
+	assert.SolvingSucceeded(r1cs, &witness)
+
+	// four receipts issued can't be covered by a proof over three quotes
+	bad := witness
+	bad.ReceiptCount = frontend.Variable{}
+	bad.ReceiptCount.Assign(4)
+	assert.SolvingFailed(r1cs, &bad)
+
+	// the initiator can't use a quote in another slot than its receipt
+	bad = witness
+	bad.Commitments = [3]frontend.Variable{}
+	bad.Receipts = [3]Signature{}
+	for i, j := range []int{1, 0, 2} {
+		bad.Commitments[i].Assign(commitments[j])
+		assignSignature(&bad.Receipts[i], receipts[j].Signature)
+	}
+	assert.SolvingFailed(r1cs, &bad)
+
+	// nor sign receipts with another key
+	bad = witness
+	bad.InitiatorKey = PublicKey{}
+	assignPublicKey(&bad.InitiatorKey, keys[0].Public().Bytes())
+	assert.SolvingFailed(r1cs, &bad)
+}
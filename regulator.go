@@ -0,0 +1,178 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// tradeReportSize is the number of field elements sent to the regulator:
+// accepted quote, winning dealer public key (x, y) and the bond fields
+const tradeReportSize = 3 + bondFieldsSize
+
+var errShortRegulatorKey = errors.New("regulator private key is too short")
+
+// Ciphertext is a trade report encrypted to the regulator key (to be used in gnark circuit).
+// It is a hashed ElGamal encryption on the BN254 twisted Edwards curve:
+// C1 = r*G and each plain value is masked with MiMC(MiMC(r*RegulatorKey), i)
+type Ciphertext struct {
+	C1   twistededwards.Point
+	Data [tradeReportSize]frontend.Variable
+}
+
+// TradeReport is what the regulator reads once the ciphertext of a RFQ proof is decrypted
+type TradeReport struct {
+	AcceptedQuote []byte
+	Dealer        signature.PublicKey
+	Bond          *Bond
+}
+
+// TradeCiphertext is the go counterpart of Ciphertext
+type TradeCiphertext struct {
+	C1   edwardsbn254.PointAffine
+	Data [tradeReportSize]fr.Element
+}
+
+// mustEncrypt constrains ct to be the encryption of plain under regulatorKey with the nonce
+func (ct *Ciphertext) mustEncrypt(cs *frontend.ConstraintSystem, hash mimc.MiMC, curve twistededwards.EdCurve,
+	regulatorKey twistededwards.Point, nonce frontend.Variable, plain [tradeReportSize]frontend.Variable) {
+
+	regulatorKey.MustBeOnCurve(cs, curve)
+
+	c1 := twistededwards.Point{}
+	c1.ScalarMulFixedBase(cs, curve.BaseX, curve.BaseY, nonce, curve)
+	cs.AssertIsEqual(ct.C1.X, c1.X)
+	cs.AssertIsEqual(ct.C1.Y, c1.Y)
+
+	shared := twistededwards.Point{}
+	shared.ScalarMulNonFixedBase(cs, &regulatorKey, nonce, curve)
+	key := hash.Hash(cs, shared.X, shared.Y)
+
+	for i := 0; i < tradeReportSize; i++ {
+		mask := hash.Hash(cs, key, cs.Constant(i))
+		cs.AssertIsEqual(ct.Data[i], cs.Add(plain[i], mask))
+	}
+}
+
+// Assign sets the witness values of the circuit ciphertext
+func (ct *Ciphertext) Assign(value *TradeCiphertext) {
+	ct.C1.X.Assign(&value.C1.X)
+	ct.C1.Y.Assign(&value.C1.Y)
+	for i := 0; i < tradeReportSize; i++ {
+		ct.Data[i].Assign(&value.Data[i])
+	}
+}
+
+// fields returns the report as field elements, in the order used by bondCircuit
+func (report *TradeReport) fields() ([tradeReportSize]fr.Element, error) {
+	var plain [tradeReportSize]fr.Element
+
+	bondFields, err := report.Bond.Fields()
+	if err != nil {
+		return plain, err
+	}
+
+	var dealer edwardsbn254.PointAffine
+	if _, err := dealer.SetBytes(report.Dealer.Bytes()); err != nil {
+		return plain, err
+	}
+
+	plain[0].SetBytes(report.AcceptedQuote)
+	plain[1].Set(&dealer.X)
+	plain[2].Set(&dealer.Y)
+	for i := 0; i < bondFieldsSize; i++ {
+		plain[3+i].SetBigInt(bondFields[i])
+	}
+	return plain, nil
+}
+
+// Encrypt encrypts the report to the regulator public key. nonce must be a fresh random scalar,
+// it is the private EncryptionNonce of bondCircuit.
+func (report *TradeReport) Encrypt(regulator signature.PublicKey, nonce *big.Int) (TradeCiphertext, error) {
+	var ct TradeCiphertext
+
+	plain, err := report.fields()
+	if err != nil {
+		return ct, err
+	}
+
+	var regulatorKey edwardsbn254.PointAffine
+	if _, err := regulatorKey.SetBytes(regulator.Bytes()); err != nil {
+		return ct, err
+	}
+
+	curve := edwardsbn254.GetEdwardsCurve()
+	ct.C1.ScalarMul(&curve.Base, nonce)
+
+	var shared edwardsbn254.PointAffine
+	shared.ScalarMul(&regulatorKey, nonce)
+
+	masks := reportMasks(&shared)
+	for i := 0; i < tradeReportSize; i++ {
+		ct.Data[i].Add(&plain[i], &masks[i])
+	}
+	return ct, nil
+}
+
+// DecryptTradeReport is used by the regulator to read a trade report from the public inputs of a RFQ proof.
+// regulator is the eddsa key whose public part was given as RegulatorKey to the circuit.
+func DecryptTradeReport(regulator signature.Signer, ct *TradeCiphertext) (*TradeReport, error) {
+
+	// private key bytes are publicKey||scalar||randSrc
+	keyBytes := regulator.Bytes()
+	if len(keyBytes) < 64 {
+		return nil, errShortRegulatorKey
+	}
+	var scalar big.Int
+	scalar.SetBytes(keyBytes[32:64])
+
+	var shared edwardsbn254.PointAffine
+	shared.ScalarMul(&ct.C1, &scalar)
+
+	masks := reportMasks(&shared)
+	var plain [tradeReportSize]fr.Element
+	for i := 0; i < tradeReportSize; i++ {
+		plain[i].Sub(&ct.Data[i], &masks[i])
+	}
+
+	var dealer eddsabn254.PublicKey
+	dealer.A.X.Set(&plain[1])
+	dealer.A.Y.Set(&plain[2])
+
+	var bondFields [bondFieldsSize]*big.Int
+	for i := 0; i < bondFieldsSize; i++ {
+		bondFields[i] = new(big.Int)
+		plain[3+i].ToBigIntRegular(bondFields[i])
+	}
+
+	var quote big.Int
+	plain[0].ToBigIntRegular(&quote)
+
+	return &TradeReport{
+		AcceptedQuote: quote.Bytes(),
+		Dealer:        &dealer,
+		Bond:          bondFromFields(bondFields),
+	}, nil
+}
+
+// reportMasks derives the values added to each field of the report from the ElGamal shared point
+func reportMasks(shared *edwardsbn254.PointAffine) [tradeReportSize]fr.Element {
+	var masks [tradeReportSize]fr.Element
+
+	var x, y big.Int
+	shared.X.ToBigIntRegular(&x)
+	shared.Y.ToBigIntRegular(&y)
+	key := new(big.Int).SetBytes(hashFields(&x, &y))
+
+	for i := 0; i < tradeReportSize; i++ {
+		masks[i].SetBytes(hashFields(key, big.NewInt(int64(i))))
+	}
+	return masks
+}
@@ -0,0 +1,136 @@
+package financial
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+type regulatorReportCircuit struct {
+	RegulatorKey PublicKey                          `gnark:",public"`
+	Report       Ciphertext                         `gnark:",public"`
+	Plain        [tradeReportSize]frontend.Variable `gnark:",private"`
+	Nonce        frontend.Variable                  `gnark:",private"`
+}
+
+func (circuit *regulatorReportCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+	circuit.Report.mustEncrypt(cs, mimc, params, circuit.RegulatorKey.A, circuit.Nonce, circuit.Plain)
+	return nil
+}
+
+func testTradeReport() (*TradeReport, signature.Signer, signature.Signer) {
+	regulator, _ := signature.EDDSA_BN254.New(rand.New(rand.NewSource(4)))
+	dealer, _ := signature.EDDSA_BN254.New(rand.New(rand.NewSource(1)))
+
+	report := &TradeReport{
+		AcceptedQuote: big.NewInt(50946500).Bytes(),
+		Dealer:        dealer.Public(),
+		Bond: &Bond{
+			Isin:   "US48128GT919",
+			Size:   "450000",
+			Ticker: "JPM  STEP 06/23/2030 Callable Step 06/23/2026 @ 2.25",
+			Coupon: bondmath.Coupon{
+				Rate:      decimal.RequireFromString("1.5"),
+				Frequency: 2,
+				DayCount:  bondmath.Thirty360,
+				Maturity:  time.Date(2030, 6, 23, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	return report, regulator, dealer
+}
+
+func TestRegulatorDecrypt(t *testing.T) {
+	report, regulator, _ := testTradeReport()
+
+	ct, err := report.Encrypt(regulator.Public(), big.NewInt(123456789))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := DecryptTradeReport(regulator, &ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted.AcceptedQuote, report.AcceptedQuote) {
+		t.Fatal("accepted quote doesn't match")
+	}
+	if !decrypted.Dealer.Equal(report.Dealer) {
+		t.Fatal("dealer doesn't match")
+	}
+	decryptedHash, _ := decrypted.Bond.Hash()
+	bondHash, _ := report.Bond.Hash()
+	if !bytes.Equal(decryptedHash, bondHash) || decrypted.Bond.Ticker != report.Bond.Ticker {
+		t.Fatal("bond doesn't match", decrypted.Bond)
+	}
+
+	// another key reads garbage
+	other, _ := signature.EDDSA_BN254.New(rand.New(rand.NewSource(5)))
+	decrypted, err = DecryptTradeReport(other, &ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(decrypted.AcceptedQuote, report.AcceptedQuote) {
+		t.Fatal("report decrypted with the wrong key")
+	}
+}
+
+func TestRegulatorReportCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit regulatorReportCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	report, regulator, _ := testTradeReport()
+	nonce := big.NewInt(987654321)
+	ct, err := report.Encrypt(regulator.Public(), nonce)
+	assert.NoError(err)
+	plain, err := report.fields()
+	assert.NoError(err)
+
+	var regulatorKey edwardsbn254.PointAffine
+	regulatorKey.SetBytes(regulator.Public().Bytes())
+
+	var witness regulatorReportCircuit
+	witness.RegulatorKey.A.X.Assign(&regulatorKey.X)
+	witness.RegulatorKey.A.Y.Assign(&regulatorKey.Y)
+	witness.Report.Assign(&ct)
+	for i := 0; i < tradeReportSize; i++ {
+		witness.Plain[i].Assign(&plain[i])
+	}
+	witness.Nonce.Assign(nonce)
+	assert.SolvingSucceeded(r1cs, &witness)
+
+	// the initiator can't hide a different quote from the regulator
+	witness.Plain[0] = frontend.Variable{}
+	witness.Plain[0].Assign(big.NewInt(50946501))
+	assert.SolvingFailed(r1cs, &witness)
+
+	// nor encrypt with a nonce other than the one behind C1
+	witness.Plain[0] = frontend.Variable{}
+	witness.Plain[0].Assign(&plain[0])
+	witness.Nonce = frontend.Variable{}
+	witness.Nonce.Assign(big.NewInt(987654322))
+	assert.SolvingFailed(r1cs, &witness)
+}
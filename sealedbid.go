@@ -0,0 +1,171 @@
+package financial
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"time"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+var (
+	errUnknownDealer      = errors.New("dealer is not asked for a quote")
+	errAlreadyCommitted   = errors.New("dealer already committed to a quote")
+	errAfterDeadline      = errors.New("commitments are closed after the deadline")
+	errBeforeDeadline     = errors.New("quotes are revealed after the deadline only")
+	errNoCommitment       = errors.New("dealer didn't commit to a quote before the deadline")
+	errCommitmentMismatch = errors.New("revealed quote doesn't open the commitment of the dealer")
+	errInvalidQuote       = errors.New("revealed quote isn't signed by the dealer for the bond")
+)
+
+// QuoteReveal is what a dealer sends the initiator once the RFQ deadline has passed:
+// the signed quote and the blinding factor of the commitment published before the deadline
+type QuoteReveal struct {
+	Dealer   signature.PublicKey
+	Quote    *SignedQuote
+	Blinding *big.Int
+}
+
+// SealQuote draws a blinding factor for a signed quote in the RFQ rfqID and returns the reveal to keep
+// until the deadline and the commitment to publish before it
+func SealQuote(dealer signature.PublicKey, rfqID *big.Int, bondHash []byte, quote *SignedQuote) (*QuoteReveal, []byte, error) {
+	blinding, err := rand.Int(rand.Reader, fr.Modulus())
+	if err != nil {
+		return nil, nil, err
+	}
+	reveal := &QuoteReveal{Dealer: dealer, Quote: quote, Blinding: blinding}
+	commitment, err := reveal.Commitment(rfqID, bondHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reveal, commitment, nil
+}
+
+// Commitment is MiMC(RFQ ID, bond hash, quote, bond quote signature, blinding), opened in bondCircuit.
+// The RFQ ID ties the sealed quote, and so its nullifier, to the RFQ it was sent to.
+func (r *QuoteReveal) Commitment(rfqID *big.Int, bondHash []byte) ([]byte, error) {
+	rx, ry, s1, s2, err := parseSignature(ecc.BN254, r.Quote.BondSignature)
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(rfqID, new(big.Int).SetBytes(bondHash), r.Quote.Quote,
+		new(big.Int).SetBytes(rx), new(big.Int).SetBytes(ry), new(big.Int).SetBytes(s1), new(big.Int).SetBytes(s2),
+		r.Blinding), nil
+}
+
+// SealedBidRFQ runs the two phases of a sealed-bid RFQ for the initiator: dealers commit to their
+// signed quote until Deadline, and reveal it after, so no quote can be shown to another dealer
+// while it can still be changed
+type SealedBidRFQ struct {
+	ID       *big.Int // unique to the RFQ, public input of bondCircuit
+	Bond     []byte   // bond hash
+	Deadline time.Time
+
+	dealers     map[string]signature.PublicKey
+	commitments map[string][]byte
+	reveals     map[string]*QuoteReveal
+	order       []string // dealers in the order their commitments arrived
+}
+
+// NewSealedBidRFQ opens the RFQ id for the bond with that hash, asking the dealers for a quote
+func NewSealedBidRFQ(id *big.Int, bondHash []byte, deadline time.Time, dealers []signature.PublicKey) *SealedBidRFQ {
+	r := &SealedBidRFQ{
+		ID:          id,
+		Bond:        bondHash,
+		Deadline:    deadline,
+		dealers:     make(map[string]signature.PublicKey),
+		commitments: make(map[string][]byte),
+		reveals:     make(map[string]*QuoteReveal),
+	}
+	for _, dealer := range dealers {
+		r.dealers[keystore.PublicKeyHex(dealer)] = dealer
+	}
+	return r
+}
+
+// OpenSealedBidRFQ opens the RFQ id for the bond identified by an ISIN, or the CUSIP of a US ISIN,
+// in the security master
+func OpenSealedBidRFQ(master SecurityMaster, id *big.Int, isin string, deadline time.Time, dealers []signature.PublicKey) (*SealedBidRFQ, error) {
+	bond, err := master.Lookup(isin)
+	if err != nil {
+		return nil, err
+	}
+	bondHash, err := bond.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return NewSealedBidRFQ(id, bondHash, deadline, dealers), nil
+}
+
+// Commit records the commitment of a dealer, received at now
+func (r *SealedBidRFQ) Commit(dealer signature.PublicKey, commitment []byte, now time.Time) error {
+	key := keystore.PublicKeyHex(dealer)
+	if _, ok := r.dealers[key]; !ok {
+		return errUnknownDealer
+	}
+	if !now.Before(r.Deadline) {
+		return errAfterDeadline
+	}
+	if _, ok := r.commitments[key]; ok {
+		return errAlreadyCommitted
+	}
+	r.commitments[key] = commitment
+	r.order = append(r.order, key)
+	return nil
+}
+
+// Reveal checks a quote revealed at now opens the commitment of its dealer and is signed for the bond
+func (r *SealedBidRFQ) Reveal(reveal *QuoteReveal, now time.Time) error {
+	key := keystore.PublicKeyHex(reveal.Dealer)
+	if _, ok := r.dealers[key]; !ok {
+		return errUnknownDealer
+	}
+	if now.Before(r.Deadline) {
+		return errBeforeDeadline
+	}
+	commitment, ok := r.commitments[key]
+	if !ok {
+		return errNoCommitment
+	}
+	opened, err := reveal.Commitment(r.ID, r.Bond)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(commitment, opened) {
+		return errCommitmentMismatch
+	}
+
+	if !verifySignature(reveal.Dealer, reveal.Quote.Signature, reveal.Quote.Quote.Bytes()) ||
+		!verifySignature(reveal.Dealer, reveal.Quote.BondSignature, bondQuoteHash(r.Bond, r.ID, reveal.Quote.Quote)) {
+		return errInvalidQuote
+	}
+	r.reveals[key] = reveal
+	return nil
+}
+
+// Commitment returns the commitment published by a dealer, public input of bondCircuit
+func (r *SealedBidRFQ) Commitment(dealer signature.PublicKey) ([]byte, bool) {
+	commitment, ok := r.commitments[keystore.PublicKeyHex(dealer)]
+	return commitment, ok
+}
+
+// Revealed returns the quote revealed by a dealer
+func (r *SealedBidRFQ) Revealed(dealer signature.PublicKey) (*QuoteReveal, bool) {
+	reveal, ok := r.reveals[keystore.PublicKeyHex(dealer)]
+	return reveal, ok
+}
+
+// quoteCommitment is the commitment of a dealer to its quote for the bond, as computed by QuoteReveal.Commitment
+func quoteCommitment(cs *frontend.ConstraintSystem, hash mimc.MiMC, rfqID, bond, quote frontend.Variable,
+	signed Signature, blinding frontend.Variable) frontend.Variable {
+
+	return hash.Hash(cs, rfqID, bond, quote, signed.R.X, signed.R.Y, signed.S1, signed.S2, blinding)
+}
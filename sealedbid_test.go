@@ -0,0 +1,148 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/signature"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+type quoteCommitmentCircuit struct {
+	RFQID      frontend.Variable `gnark:",public"`
+	Bond       frontend.Variable `gnark:",public"`
+	Commitment frontend.Variable `gnark:",public"`
+	Quote      frontend.Variable `gnark:",private"`
+	Signed     Signature         `gnark:",private"`
+	Blinding   frontend.Variable `gnark:",private"`
+}
+
+func (circuit *quoteCommitmentCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	hash, err := mimc.NewMiMC("seed", curveID)
+	if err != nil {
+		return err
+	}
+	commitment := quoteCommitment(cs, hash, circuit.RFQID, circuit.Bond, circuit.Quote, circuit.Signed, circuit.Blinding)
+	cs.AssertIsEqual(circuit.Commitment, commitment)
+	return nil
+}
+
+func TestSealedBidRFQ(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	assert.NoError(err)
+	bond := lookupTestBond(master, "CA29250NAT24")
+	bondHash, err := bond.Hash()
+	assert.NoError(err)
+
+	var keys [3]signature.Signer
+	var dealers []signature.PublicKey
+	for i := range keys {
+		keys[i], err = keystore.Generate()
+		assert.NoError(err)
+		dealers = append(dealers, keys[i].Public())
+	}
+	outsider, err := keystore.Generate()
+	assert.NoError(err)
+
+	deadline := time.Date(2021, 11, 19, 16, 0, 0, 0, time.UTC)
+	before, after := deadline.Add(-time.Minute), deadline.Add(time.Minute)
+	rfq, err := OpenSealedBidRFQ(master, big.NewInt(1), bond.Isin, deadline, dealers)
+	assert.NoError(err)
+	assert.Equal(bondHash, rfq.Bond)
+	_, err = OpenSealedBidRFQ(master, big.NewInt(1), "US0378331005", deadline, dealers)
+	assert.True(errors.Is(err, errUnknownBond), err)
+	_, err = OpenSealedBidRFQ(master, big.NewInt(1), "CA29250NAT2X", deadline, dealers)
+	assert.Error(err)
+
+	// the last dealer commits too late
+	var reveals [3]*QuoteReveal
+	for i, key := range keys {
+		signed, err := NewLocalSigner(key, nil).SignQuote(bond, rfq.ID, big.NewInt(int64(50946500+i*1000)))
+		assert.NoError(err)
+		var commitment []byte
+		reveals[i], commitment, err = SealQuote(key.Public(), rfq.ID, bondHash, signed)
+		assert.NoError(err)
+		if i < 2 {
+			assert.NoError(rfq.Commit(key.Public(), commitment, before))
+			assert.True(errors.Is(rfq.Commit(key.Public(), commitment, before), errAlreadyCommitted))
+		} else {
+			assert.True(errors.Is(rfq.Commit(key.Public(), commitment, after), errAfterDeadline))
+		}
+	}
+	assert.True(errors.Is(rfq.Commit(outsider.Public(), []byte{1}, before), errUnknownDealer))
+
+	// quotes are only revealed after the deadline
+	assert.True(errors.Is(rfq.Reveal(reveals[0], before), errBeforeDeadline))
+
+	assert.NoError(rfq.Reveal(reveals[0], after))
+	revealed, ok := rfq.Revealed(dealers[0])
+	assert.True(ok)
+	assert.Equal(reveals[0], revealed)
+
+	// a dealer missing the deadline can't get its quote in
+	assert.True(errors.Is(rfq.Reveal(reveals[2], after), errNoCommitment))
+
+	// nor change its quote once committed
+	changed := *reveals[1]
+	changed.Quote, err = NewLocalSigner(keys[1], nil).SignQuote(bond, rfq.ID, big.NewInt(40000000))
+	assert.NoError(err)
+	assert.True(errors.Is(rfq.Reveal(&changed, after), errCommitmentMismatch))
+	changed = *reveals[1]
+	changed.Blinding = new(big.Int).Add(reveals[1].Blinding, big.NewInt(1))
+	assert.True(errors.Is(rfq.Reveal(&changed, after), errCommitmentMismatch))
+	assert.NoError(rfq.Reveal(reveals[1], after))
+
+	// a quote sealed for this RFQ doesn't open in another one
+	other := NewSealedBidRFQ(big.NewInt(2), bondHash, deadline, dealers)
+	commitment, _ := rfq.Commitment(dealers[0])
+	assert.NoError(other.Commit(dealers[0], commitment, before))
+	assert.True(errors.Is(other.Reveal(reveals[0], after), errCommitmentMismatch))
+
+	// nor is a quote signed for this RFQ valid when sealed for another one
+	resealed, commitment, err := SealQuote(dealers[1], other.ID, bondHash, reveals[1].Quote)
+	assert.NoError(err)
+	assert.NoError(other.Commit(dealers[1], commitment, before))
+	assert.True(errors.Is(other.Reveal(resealed, after), errInvalidQuote))
+	commitment, _ = rfq.Commitment(dealers[0])
+
+	// the circuit opens the same commitments
+	var circuit quoteCommitmentCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	var witness quoteCommitmentCircuit
+	witness.RFQID.Assign(rfq.ID)
+	witness.Bond.Assign(bondHash)
+	witness.Commitment.Assign(commitment)
+	witness.Quote.Assign(reveals[0].Quote.Quote)
+	assignSignature(&witness.Signed, reveals[0].Quote.BondSignature)
+	witness.Blinding.Assign(reveals[0].Blinding)
+	assert.SolvingSucceeded(r1cs, &witness)
+
+	bad := witness
+	bad.Quote = frontend.Variable{}
+	bad.Quote.Assign(reveals[1].Quote.Quote)
+	assert.SolvingFailed(r1cs, &bad)
+
+	bad = witness
+	bad.Commitment = frontend.Variable{}
+	otherCommitment, _ := rfq.Commitment(dealers[1])
+	bad.Commitment.Assign(otherCommitment)
+	assert.SolvingFailed(r1cs, &bad)
+
+	bad = witness
+	bad.RFQID = frontend.Variable{}
+	bad.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, &bad)
+}
@@ -0,0 +1,238 @@
+// Package secid parses and validates securities identifiers: ISIN, CUSIP and FIGI.
+package secid
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kind is the type of a securities identifier
+type Kind int
+
+const (
+	ISIN  Kind = iota // International Securities Identification Number, ISO 6166
+	CUSIP             // CUSIP Global Services identifier of North American securities
+	FIGI              // Financial Instrument Global Identifier
+)
+
+const (
+	isinLength  = 12
+	cusipLength = 9
+	figiLength  = 12
+)
+
+var (
+	errLength        = errors.New("identifier has the wrong length")
+	errCharacter     = errors.New("identifier has an invalid character")
+	errCheckDigit    = errors.New("identifier check digit doesn't match")
+	errCountry       = errors.New("ISIN country code must be two letters")
+	errNotUS         = errors.New("not a US ISIN")
+	errFigiPrefix    = errors.New("FIGI prefix is not allowed")
+	errUnknownFormat = errors.New("not an ISIN, CUSIP or FIGI")
+)
+
+func (k Kind) String() string {
+	switch k {
+	case ISIN:
+		return "ISIN"
+	case CUSIP:
+		return "CUSIP"
+	case FIGI:
+		return "FIGI"
+	}
+	return "unknown"
+}
+
+// Parse returns the kind of a valid identifier
+func Parse(id string) (Kind, error) {
+	if ValidateCUSIP(id) == nil {
+		return CUSIP, nil
+	}
+	if ValidateFIGI(id) == nil {
+		return FIGI, nil
+	}
+	if ValidateISIN(id) == nil {
+		return ISIN, nil
+	}
+	return 0, errUnknownFormat
+}
+
+// charValue returns the value of a digit or upper case letter: 0-9 then A=10 to Z=35
+func charValue(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+// ISINCheckDigit returns the check digit of the first 11 characters of an ISIN: the Luhn algorithm
+// on the digits obtained by replacing each letter with its value
+func ISINCheckDigit(body string) (byte, error) {
+	if len(body) != isinLength-1 {
+		return 0, errLength
+	}
+	var digits []int
+	for i := 0; i < len(body); i++ {
+		v, ok := charValue(body[i])
+		if !ok {
+			return 0, errCharacter
+		}
+		if v >= 10 {
+			digits = append(digits, v/10)
+		}
+		digits = append(digits, v%10)
+	}
+
+	// the rightmost digit of the body is doubled
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := digits[len(digits)-1-i]
+		if i%2 == 0 {
+			d *= 2
+		}
+		sum += d/10 + d%10
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+// ValidateISIN checks the format and the check digit of an ISIN
+func ValidateISIN(isin string) error {
+	if len(isin) != isinLength {
+		return errLength
+	}
+	if !isLetter(isin[0]) || !isLetter(isin[1]) {
+		return errCountry
+	}
+	check, err := ISINCheckDigit(isin[:isinLength-1])
+	if err != nil {
+		return err
+	}
+	if isin[isinLength-1] != check {
+		return errCheckDigit
+	}
+	return nil
+}
+
+// modulus10 is the "double add double" check digit of CUSIP and FIGI: every second value
+// is doubled and the digits of all values are added
+func modulus10(body string, value func(byte) (int, bool)) (byte, error) {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		v, ok := value(body[i])
+		if !ok {
+			return 0, errCharacter
+		}
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+// cusipValue extends charValue with the characters of private placement CUSIPs
+func cusipValue(c byte) (int, bool) {
+	switch c {
+	case '*':
+		return 36, true
+	case '@':
+		return 37, true
+	case '#':
+		return 38, true
+	}
+	return charValue(c)
+}
+
+// CUSIPCheckDigit returns the check digit of the first 8 characters of a CUSIP
+func CUSIPCheckDigit(body string) (byte, error) {
+	if len(body) != cusipLength-1 {
+		return 0, errLength
+	}
+	return modulus10(body, cusipValue)
+}
+
+// ValidateCUSIP checks the format and the check digit of a CUSIP
+func ValidateCUSIP(cusip string) error {
+	if len(cusip) != cusipLength {
+		return errLength
+	}
+	check, err := CUSIPCheckDigit(cusip[:cusipLength-1])
+	if err != nil {
+		return err
+	}
+	if cusip[cusipLength-1] != check {
+		return errCheckDigit
+	}
+	return nil
+}
+
+// figiPrefixes are not allowed as the first two characters of a FIGI, to avoid confusion with ISINs
+var figiPrefixes = []string{"BS", "BM", "GG", "GB", "GH", "KY", "VG"}
+
+// ValidateFIGI checks the format and the check digit of a FIGI: two consonants, G,
+// 8 consonants or digits, then the check digit
+func ValidateFIGI(figi string) error {
+	if len(figi) != figiLength {
+		return errLength
+	}
+	for _, prefix := range figiPrefixes {
+		if strings.HasPrefix(figi, prefix) {
+			return errFigiPrefix
+		}
+	}
+	if figi[2] != 'G' {
+		return errCharacter
+	}
+	for i := 0; i < figiLength-1; i++ {
+		c := figi[i]
+		if i == 2 || (i > 2 && c >= '0' && c <= '9') {
+			continue
+		}
+		if !isLetter(c) || strings.IndexByte("AEIOU", c) >= 0 {
+			return errCharacter
+		}
+	}
+	check, err := modulus10(figi[:figiLength-1], charValue)
+	if err != nil {
+		return err
+	}
+	if figi[figiLength-1] != check {
+		return errCheckDigit
+	}
+	return nil
+}
+
+// CUSIPToISIN returns the US ISIN of a CUSIP
+func CUSIPToISIN(cusip string) (string, error) {
+	if err := ValidateCUSIP(cusip); err != nil {
+		return "", err
+	}
+	body := "US" + cusip
+	check, err := ISINCheckDigit(body)
+	if err != nil {
+		return "", err
+	}
+	return body + string(check), nil
+}
+
+// ISINToCUSIP returns the CUSIP of a US ISIN
+func ISINToCUSIP(isin string) (string, error) {
+	if err := ValidateISIN(isin); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(isin, "US") {
+		return "", errNotUS
+	}
+	cusip := isin[2 : 2+cusipLength]
+	if err := ValidateCUSIP(cusip); err != nil {
+		return "", err
+	}
+	return cusip, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,68 @@
+package secid
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		id    string
+		kind  Kind
+		valid bool
+	}{
+		{"US0378331005", ISIN, true},
+		{"CA29250NAT24", ISIN, true},
+		{"US46625HKC33", ISIN, true},
+		{"US0378331006", ISIN, false},
+		{"1S0378331005", ISIN, false},
+		{"037833100", CUSIP, true},
+		{"46625HKC3", CUSIP, true},
+		{"89114QCR7", CUSIP, true},
+		{"6625HKC3", CUSIP, false},
+		{"46625HKC4", CUSIP, false},
+		{"BBG000BLNNH6", FIGI, true},
+		{"BBG000BLNNH5", FIGI, false},
+		{"BBA000BLNNH6", FIGI, false},
+		{"GGG000BLNNH6", FIGI, false},
+	}
+	for _, c := range cases {
+		var err error
+		switch c.kind {
+		case ISIN:
+			err = ValidateISIN(c.id)
+		case CUSIP:
+			err = ValidateCUSIP(c.id)
+		case FIGI:
+			err = ValidateFIGI(c.id)
+		}
+		if (err == nil) != c.valid {
+			t.Errorf("%s %v: got %v, expected valid %v", c.kind, c.id, err, c.valid)
+		}
+		if kind, err := Parse(c.id); c.valid && (err != nil || kind != c.kind) {
+			t.Errorf("parse %v: got %v %v, expected %v", c.id, kind, err, c.kind)
+		}
+	}
+}
+
+func TestCUSIPToISIN(t *testing.T) {
+	pairs := [][2]string{
+		{"037833100", "US0378331005"},
+		{"46625HKC3", "US46625HKC33"},
+		{"89114QCR7", "US89114QCR74"},
+	}
+	for _, pair := range pairs {
+		isin, err := CUSIPToISIN(pair[0])
+		if err != nil || isin != pair[1] {
+			t.Errorf("ISIN of %v: got %v %v, expected %v", pair[0], isin, err, pair[1])
+		}
+		cusip, err := ISINToCUSIP(pair[1])
+		if err != nil || cusip != pair[0] {
+			t.Errorf("CUSIP of %v: got %v %v, expected %v", pair[1], cusip, err, pair[0])
+		}
+	}
+
+	if _, err := CUSIPToISIN("6625HKC3"); err == nil {
+		t.Error("an invalid CUSIP has no ISIN")
+	}
+	if _, err := ISINToCUSIP("CA29250NAT24"); err == nil {
+		t.Error("a Canadian ISIN has no US CUSIP")
+	}
+}
@@ -0,0 +1,309 @@
+package financial
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bloconuts/v0/bondmath"
+	"bloconuts/v0/secid"
+
+	"github.com/shopspring/decimal"
+)
+
+// dateLayout is the format of the dates in a security master
+const dateLayout = "2006-01-02"
+
+var (
+	errDuplicateBond   = errors.New("identifier is listed twice in the security master")
+	errUnknownBond     = errors.New("bond not found in the security master")
+	errMasterFormat    = errors.New("security master must be a .json or .csv file")
+	errScheduleEntry   = errors.New("schedule entries must be date:value")
+	errMissingCSVField = errors.New("security master csv has no isin column")
+)
+
+// SecurityMaster holds the reference data of bonds, keyed by ISIN
+type SecurityMaster map[string]*Bond
+
+// securityRecord is a bond as written in a security master file. Schedules are dates (YYYY-MM-DD)
+// with a price, or a rate for steps. The maturity of a perpetual bond is one of its coupon dates.
+type securityRecord struct {
+	Isin      string           `json:"isin"`
+	Ticker    string           `json:"ticker"`
+	Size      json.Number      `json:"size"`
+	Type      string           `json:"type"`
+	Coupon    string           `json:"coupon"`
+	Frequency int              `json:"frequency"`
+	DayCount  string           `json:"dayCount"`
+	Maturity  string           `json:"maturity"`
+	Calls     []scheduleRecord `json:"calls"`
+	Puts      []scheduleRecord `json:"puts"`
+	Steps     []scheduleRecord `json:"steps"`
+	Index     string           `json:"index"`
+	Spread    string           `json:"spread"`
+}
+
+type scheduleRecord struct {
+	Date  string `json:"date"`
+	Price string `json:"price"`
+	Rate  string `json:"rate"`
+}
+
+// bond converts the record to a validated Bond
+func (r *securityRecord) bond() (*Bond, error) {
+	instrumentType := Vanilla
+	if r.Type != "" {
+		t, err := ParseInstrumentType(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		instrumentType = t
+	}
+	rate, err := decimal.NewFromString(r.Coupon)
+	if err != nil {
+		return nil, err
+	}
+	dayCount, err := bondmath.ParseDayCount(r.DayCount)
+	if err != nil {
+		return nil, err
+	}
+	maturity, err := time.Parse(dateLayout, r.Maturity)
+	if err != nil {
+		return nil, err
+	}
+	if instrumentType == Perpetual {
+		maturity = bondmath.PerpetualMaturity(maturity)
+	}
+
+	bond := &Bond{
+		Isin:   r.Isin,
+		Size:   r.Size.String(),
+		Ticker: r.Ticker,
+		Type:   instrumentType,
+		Coupon: bondmath.Coupon{
+			Rate:      rate,
+			Frequency: r.Frequency,
+			DayCount:  dayCount,
+			Maturity:  maturity,
+		},
+	}
+	for _, call := range r.Calls {
+		date, price, err := call.parse(call.Price)
+		if err != nil {
+			return nil, err
+		}
+		bond.Calls = append(bond.Calls, bondmath.Call{Date: date, Price: price})
+	}
+	for _, put := range r.Puts {
+		date, price, err := put.parse(put.Price)
+		if err != nil {
+			return nil, err
+		}
+		bond.Puts = append(bond.Puts, bondmath.Put{Date: date, Price: price})
+	}
+	for _, step := range r.Steps {
+		date, rate, err := step.parse(step.Rate)
+		if err != nil {
+			return nil, err
+		}
+		bond.Coupon.Steps = append(bond.Coupon.Steps, bondmath.Step{Date: date, Rate: rate})
+	}
+	if r.Index != "" {
+		spread := decimal.Zero
+		if r.Spread != "" {
+			if spread, err = decimal.NewFromString(r.Spread); err != nil {
+				return nil, err
+			}
+		}
+		bond.Coupon.Floating = &bondmath.FloatingRate{Index: r.Index, Spread: spread}
+	}
+
+	if _, err := bond.Fields(); err != nil {
+		return nil, err
+	}
+	return bond, nil
+}
+
+func (s *scheduleRecord) parse(value string) (time.Time, decimal.Decimal, error) {
+	date, err := time.Parse(dateLayout, s.Date)
+	if err != nil {
+		return date, decimal.Zero, err
+	}
+	v, err := decimal.NewFromString(value)
+	return date, v, err
+}
+
+// Add validates a bond and adds it to the security master
+func (m SecurityMaster) Add(bond *Bond) error {
+	if _, err := bond.Fields(); err != nil {
+		return err
+	}
+	if _, ok := m[bond.Isin]; ok {
+		return fmt.Errorf("%s: %w", bond.Isin, errDuplicateBond)
+	}
+	m[bond.Isin] = bond
+	return nil
+}
+
+// Lookup returns a copy of the bond identified by an ISIN, or by the CUSIP of a US ISIN.
+// The copy, schedules and floating rate terms included, can be changed, for instance to the size of
+// a RFQ, without changing the security master.
+func (m SecurityMaster) Lookup(id string) (*Bond, error) {
+	isin := id
+	if kind, err := secid.Parse(id); err == nil && kind == secid.CUSIP {
+		if isin, err = secid.CUSIPToISIN(id); err != nil {
+			return nil, err
+		}
+	}
+	bond, ok := m[isin]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, errUnknownBond)
+	}
+	return bond.clone(), nil
+}
+
+// clone returns a copy of the bond sharing no schedule or floating rate terms with it
+func (bond *Bond) clone() *Bond {
+	c := *bond
+	if bond.Calls != nil {
+		c.Calls = append(make(bondmath.CallSchedule, 0, len(bond.Calls)), bond.Calls...)
+	}
+	if bond.Puts != nil {
+		c.Puts = append(make(bondmath.PutSchedule, 0, len(bond.Puts)), bond.Puts...)
+	}
+	if bond.Coupon.Steps != nil {
+		c.Coupon.Steps = append(make(bondmath.StepSchedule, 0, len(bond.Coupon.Steps)), bond.Coupon.Steps...)
+	}
+	if bond.Coupon.Floating != nil {
+		floating := *bond.Coupon.Floating
+		c.Coupon.Floating = &floating
+	}
+	return &c
+}
+
+// LoadSecurityMaster reads a security master from a .json or .csv file
+func LoadSecurityMaster(path string) (SecurityMaster, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ReadSecurityMasterJSON(f)
+	case ".csv":
+		return ReadSecurityMasterCSV(f)
+	}
+	return nil, errMasterFormat
+}
+
+// ReadSecurityMasterJSON reads a security master written as {"bonds": [...]}
+func ReadSecurityMasterJSON(r io.Reader) (SecurityMaster, error) {
+	var file struct {
+		Bonds []securityRecord `json:"bonds"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	return newSecurityMaster(file.Bonds)
+}
+
+// ReadSecurityMasterCSV reads a security master with a header row naming the columns:
+// isin, ticker, size, type, coupon, frequency, dayCount, maturity, calls, puts, steps, index and spread.
+// Schedules are lists of date:value separated by ';'.
+func ReadSecurityMasterCSV(r io.Reader) (SecurityMaster, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errMissingCSVField
+	}
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["isin"]; !ok {
+		return nil, errMissingCSVField
+	}
+
+	records := make([]securityRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		get := func(name string) string {
+			if i, ok := columns[strings.ToLower(name)]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		var record securityRecord
+		record.Isin = get("isin")
+		record.Ticker = get("ticker")
+		record.Size = json.Number(get("size"))
+		record.Type = get("type")
+		record.Coupon = get("coupon")
+		record.DayCount = get("dayCount")
+		record.Maturity = get("maturity")
+		record.Index = get("index")
+		record.Spread = get("spread")
+		if frequency := get("frequency"); frequency != "" {
+			if _, err := fmt.Sscan(frequency, &record.Frequency); err != nil {
+				return nil, fmt.Errorf("%s: %w", record.Isin, err)
+			}
+		}
+		if record.Calls, err = parseCSVSchedule(get("calls"), false); err != nil {
+			return nil, fmt.Errorf("%s: %w", record.Isin, err)
+		}
+		if record.Puts, err = parseCSVSchedule(get("puts"), false); err != nil {
+			return nil, fmt.Errorf("%s: %w", record.Isin, err)
+		}
+		if record.Steps, err = parseCSVSchedule(get("steps"), true); err != nil {
+			return nil, fmt.Errorf("%s: %w", record.Isin, err)
+		}
+		records = append(records, record)
+	}
+	return newSecurityMaster(records)
+}
+
+// parseCSVSchedule reads date:value entries separated by ';', values are rates if rates is true, prices otherwise
+func parseCSVSchedule(s string, rates bool) ([]scheduleRecord, error) {
+	var schedule []scheduleRecord
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			return nil, errScheduleEntry
+		}
+		record := scheduleRecord{Date: parts[0]}
+		if rates {
+			record.Rate = parts[1]
+		} else {
+			record.Price = parts[1]
+		}
+		schedule = append(schedule, record)
+	}
+	return schedule, nil
+}
+
+func newSecurityMaster(records []securityRecord) (SecurityMaster, error) {
+	master := make(SecurityMaster, len(records))
+	for i := range records {
+		bond, err := records[i].bond()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", records[i].Isin, err)
+		}
+		if err := master.Add(bond); err != nil {
+			return nil, err
+		}
+	}
+	return master, nil
+}
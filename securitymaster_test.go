@@ -0,0 +1,99 @@
+package financial
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/shopspring/decimal"
+)
+
+func TestSecurityMaster(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	master, err := LoadSecurityMaster("testdata/bonds.json")
+	assert.NoError(err)
+	assert.Equal(5, len(master))
+
+	csvMaster, err := LoadSecurityMaster("testdata/bonds.csv")
+	assert.NoError(err)
+	assert.Equal(5, len(csvMaster))
+
+	// the same bond read from json or csv has the same hash
+	for _, isin := range []string{"CA29250NAT24", "US48128GT919", "US89114QCR74"} {
+		fromJSON, err := master.Lookup(isin)
+		assert.NoError(err)
+		fromCSV, err := csvMaster.Lookup(isin)
+		assert.NoError(err)
+
+		jsonHash, err := fromJSON.Hash()
+		assert.NoError(err)
+		csvHash, err := fromCSV.Hash()
+		assert.NoError(err)
+		assert.True(bytes.Equal(jsonHash, csvHash), isin)
+	}
+
+	step, err := master.Lookup("US48128GT919")
+	assert.NoError(err)
+	assert.Equal(StepUp, step.Type)
+	assert.Equal(1, len(step.Calls))
+	assert.Equal(1, len(step.Coupon.Steps))
+
+	perpetual, err := csvMaster.Lookup("XS1234567896")
+	assert.NoError(err)
+	assert.Equal(Perpetual, perpetual.Type)
+
+	// US bonds are found by CUSIP too, and the lookup returns a copy
+	callable, err := master.Lookup("46625HKC3")
+	assert.NoError(err)
+	assert.Equal("US46625HKC33", callable.Isin)
+	callable.Size = "625000"
+	callable.Calls[0].Price = decimal.NewFromInt(101)
+	callable.Calls = append(callable.Calls, callable.Calls[0])
+	callable, err = master.Lookup("US46625HKC33")
+	assert.NoError(err)
+	assert.Equal("550000", callable.Size)
+	assert.Equal(master["US46625HKC33"].Calls, callable.Calls)
+	assert.True(callable.Calls[0].Price.Equal(decimal.NewFromInt(100)), callable.Calls[0].Price)
+
+	// nor do schedules and floating rate terms
+	step.Coupon.Steps[0].Rate = decimal.Zero
+	frn, err := master.Lookup("US89114QCR74")
+	assert.NoError(err)
+	frn.Coupon.Floating.Spread = decimal.Zero
+	assert.False(master["US48128GT919"].Coupon.Steps[0].Rate.IsZero())
+	assert.False(master["US89114QCR74"].Coupon.Floating.Spread.IsZero())
+
+	_, err = master.Lookup("US0378331005")
+	assert.Error(err)
+	assert.Error(master.Add(callable))
+}
+
+func TestSecurityMasterErrors(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	invalid := []string{
+		// the check digit is wrong
+		`{"bonds": [{"isin": "CA29250NAT25", "ticker": "ENB", "size": 1, "coupon": "1", "frequency": 2, "dayCount": "30/360", "maturity": "2025-09-10"}]}`,
+		// a callable bond needs a call schedule
+		`{"bonds": [{"isin": "CA29250NAT24", "ticker": "ENB", "size": 1, "type": "Callable", "coupon": "1", "frequency": 2, "dayCount": "30/360", "maturity": "2025-09-10"}]}`,
+		`{"bonds": [{"isin": "CA29250NAT24", "ticker": "ENB", "size": 1, "coupon": "1", "frequency": 2, "dayCount": "30/365", "maturity": "2025-09-10"}]}`,
+		`{"bonds": [{"isin": "CA29250NAT24", "ticker": "ENB", "size": 1, "coupon": "1", "frequency": 2, "dayCount": "30/360", "maturity": "10/09/2025"}]}`,
+		`{"bonds": [
+			{"isin": "CA29250NAT24", "ticker": "ENB", "size": 1, "coupon": "1", "frequency": 2, "dayCount": "30/360", "maturity": "2025-09-10"},
+			{"isin": "CA29250NAT24", "ticker": "ENB", "size": 1, "coupon": "1", "frequency": 2, "dayCount": "30/360", "maturity": "2025-09-10"}]}`,
+	}
+	for _, s := range invalid {
+		_, err := ReadSecurityMasterJSON(strings.NewReader(s))
+		assert.Error(err, s)
+	}
+
+	_, err := ReadSecurityMasterCSV(strings.NewReader("ticker,size\nENB,1\n"))
+	assert.Error(err)
+	_, err = ReadSecurityMasterCSV(strings.NewReader("isin,ticker,size,type,coupon,frequency,dayCount,maturity,calls\n" +
+		"US46625HKC33,JPM,1,Callable,3.125,2,30/360,2025-01-23,2024-01-23\n"))
+	assert.Error(err)
+	_, err = LoadSecurityMaster("testdata/bonds.txt")
+	assert.Error(err)
+}
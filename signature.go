@@ -0,0 +1,67 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// signatureSize is the size of a binary eddsa signature, R compressed then S
+const signatureSize = 64
+
+var (
+	errSignatureSize         = errors.New("signature must be 64 bytes")
+	errNonCanonicalSignature = errors.New("signature S isn't smaller than the subgroup order")
+)
+
+// subgroupOrder is the order of the base point of the BN254 twisted Edwards curve.
+// S and S + subgroupOrder verify the same message, so only S < subgroupOrder is accepted.
+var subgroupOrder = edwardsbn254.GetEdwardsCurve().Order
+
+// checkCanonical returns an error if S of a binary signature isn't reduced modulo the subgroup order
+func checkCanonical(buf []byte) error {
+	if len(buf) != signatureSize {
+		return errSignatureSize
+	}
+	if new(big.Int).SetBytes(buf[32:]).Cmp(&subgroupOrder) >= 0 {
+		return errNonCanonicalSignature
+	}
+	return nil
+}
+
+// verifySignature checks sig is the canonical MiMC eddsa signature of message by key
+func verifySignature(key signature.PublicKey, sig, message []byte) bool {
+	if checkCanonical(sig) != nil {
+		return false
+	}
+	ok, _ := key.Verify(sig, message, hash.MIMC_BN254.New("seed"))
+	return ok
+}
+
+// mustBeCanonical asserts S = 2^128*S1 + S2 of sig is smaller than the subgroup order.
+// S2 < 2^128 and S1 <= (order-1) >> 128 keep S below the field modulus, so it can be compared.
+func mustBeCanonical(cs *frontend.ConstraintSystem, sig Signature) {
+	basis := new(big.Int).Lsh(big.NewInt(1), 128)
+	maxS := new(big.Int).Sub(&subgroupOrder, big.NewInt(1))
+
+	cs.AssertIsLessOrEqual(sig.S2, new(big.Int).Sub(basis, big.NewInt(1)))
+	cs.AssertIsLessOrEqual(sig.S1, new(big.Int).Rsh(maxS, 128))
+	cs.AssertIsLessOrEqual(cs.Add(cs.Mul(sig.S1, basis), sig.S2), maxS)
+}
+
+// mustBeSignedQuote verifies signed is the canonical signature of quote for the bond in the RFQ rfqID by key,
+// the message of bondQuoteHash
+func mustBeSignedQuote(cs *frontend.ConstraintSystem, hash mimc.MiMC, curve twistededwards.EdCurve,
+	bond, rfqID, quote frontend.Variable, signed Signature, key PublicKey) {
+
+	key.Curve = curve
+	mustBeCanonical(cs, signed)
+	eddsa.Verify(cs, signed, hash.Hash(cs, bond, rfqID, quote), key)
+}
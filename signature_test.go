@@ -0,0 +1,112 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"bloconuts/v0/keystore"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+type canonicalSignatureCircuit struct {
+	PublicKey PublicKey         `gnark:",public"`
+	Message   frontend.Variable `gnark:",public"`
+	Signature Signature         `gnark:",private"`
+}
+
+func (circuit *canonicalSignatureCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mustBeCanonical(cs, circuit.Signature)
+	circuit.PublicKey.Curve = params
+	eddsa.Verify(cs, circuit.Signature, circuit.Message, circuit.PublicKey)
+	return nil
+}
+
+// malleate returns the signature with S + subgroupOrder, which verifies the same message
+func malleate(sig []byte) []byte {
+	s := new(big.Int).SetBytes(sig[32:])
+	s.Add(s, &subgroupOrder)
+	malleated := make([]byte, signatureSize)
+	copy(malleated, sig[:32])
+	s.FillBytes(malleated[32:])
+	return malleated
+}
+
+func TestCanonicalSignature(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	key, err := keystore.Generate()
+	assert.NoError(err)
+	message := big.NewInt(50946500)
+	sig, err := key.Sign(message.Bytes(), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	malleated := malleate(sig)
+
+	// gnark-crypto accepts both signatures, the parser and verifySignature only the canonical one
+	ok, err := key.Public().Verify(malleated, message.Bytes(), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.True(ok)
+	assert.True(verifySignature(key.Public(), sig, message.Bytes()))
+	assert.False(verifySignature(key.Public(), malleated, message.Bytes()))
+
+	_, _, _, _, err = parseSignature(ecc.BN254, sig)
+	assert.NoError(err)
+	_, _, _, _, err = parseSignature(ecc.BN254, malleated)
+	assert.True(errors.Is(err, errNonCanonicalSignature), err)
+	_, _, _, _, err = parseSignature(ecc.BN254, sig[:40])
+	assert.True(errors.Is(err, errSignatureSize), err)
+	var w Signature
+	assert.Error(assignSignature(&w, malleated))
+
+	// a malleated receipt isn't a receipt
+	receipt, err := IssueReceipt(key, message.Bytes(), 0, message.Bytes())
+	assert.NoError(err)
+	assert.True(receipt.Verify(key.Public()))
+	receipt.Signature = malleate(receipt.Signature)
+	assert.False(receipt.Verify(key.Public()))
+
+	var circuit canonicalSignatureCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	// witness sets the halves of S without the checks of the parser
+	witness := func(sig []byte, s1, s2 *big.Int) *canonicalSignatureCircuit {
+		var w canonicalSignatureCircuit
+		assignPublicKey(&w.PublicKey, key.Public().Bytes())
+		w.Message.Assign(message)
+		rx, ry := parsePoint(ecc.BN254, sig)
+		w.Signature.R.X.Assign(rx)
+		w.Signature.R.Y.Assign(ry)
+		w.Signature.S1.Assign(s1)
+		w.Signature.S2.Assign(s2)
+		return &w
+	}
+	halves := func(sig []byte) (*big.Int, *big.Int) {
+		return new(big.Int).SetBytes(sig[32:48]), new(big.Int).SetBytes(sig[48:])
+	}
+
+	s1, s2 := halves(sig)
+	assert.SolvingSucceeded(r1cs, witness(sig, s1, s2))
+
+	// S + order
+	m1, m2 := halves(malleated)
+	assert.SolvingFailed(r1cs, witness(malleated, m1, m2))
+
+	// the same S split differently
+	basis := new(big.Int).Lsh(big.NewInt(1), 128)
+	if s1.Sign() > 0 {
+		assert.SolvingFailed(r1cs, witness(sig, new(big.Int).Sub(s1, big.NewInt(1)), new(big.Int).Add(s2, basis)))
+	}
+}
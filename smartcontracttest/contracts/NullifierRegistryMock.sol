@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: AML
+pragma solidity ^0.8.0;
+
+import "../../circuit/registry.sol";
+
+// NullifierRegistryMock exposes the nullifier bookkeeping of RFQRegistry without a proof
+contract NullifierRegistryMock is NullifierRegistry {
+    function nullify(uint256[] memory nullifiers) public {
+        _nullify(nullifiers);
+    }
+}
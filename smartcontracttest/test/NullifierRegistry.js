@@ -0,0 +1,45 @@
+const NullifierRegistryMock = artifacts.require("NullifierRegistryMock");
+
+contract("NullifierRegistry", async (accounts) => {
+
+  let registry;
+
+  beforeEach(async () => {
+    registry = await NullifierRegistryMock.new();
+  })
+
+  // nullifiers of two signed quotes, as read by RFQRegistry from the public inputs of a proof
+  const quote = "8411573650913469426716089109437592718204155736233124853108219011390862373745";
+  const other = "2176457210339126312713390106212345402342671826407651062372341212934811652095";
+
+  async function reverts(promise) {
+    try {
+      await promise;
+    } catch (err) {
+      return err.message.includes("nullifier already used");
+    }
+    return false;
+  }
+
+  it("should record the nullifiers of an accepted proof", async () => {
+    await registry.nullify([quote, other]);
+    assert.equal(true, await registry.nullified(quote));
+    assert.equal(true, await registry.nullified(other));
+  });
+
+  it("should reject a second proof using the same signed quote", async () => {
+    await registry.nullify([quote]);
+    assert.equal(true, await reverts(registry.nullify([quote])));
+  });
+
+  it("should reject a proof reusing one quote among new ones and record none", async () => {
+    await registry.nullify([quote]);
+    assert.equal(true, await reverts(registry.nullify([other, quote])));
+    assert.equal(false, await registry.nullified(other));
+  });
+
+  it("should reject a proof using a quote twice", async () => {
+    assert.equal(true, await reverts(registry.nullify([other, other])));
+  });
+
+});
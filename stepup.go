@@ -0,0 +1,128 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/shopspring/decimal"
+)
+
+// maxSteps is the number of coupon steps a bond can have in a circuit
+const maxSteps = 4
+
+// unused steps are on the last supported date so they never apply
+var lastDate = time.Date(maxYear, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var (
+	errTooManySteps   = errors.New("too many coupon steps")
+	errStepsNotSorted = errors.New("coupon steps are not in date order")
+	errInvalidStep    = errors.New("step rate must be positive with at most 4 decimals")
+)
+
+// StepCoupon is the step-up schedule of a bond (to be used in gnark circuit):
+// the coupon rate is Rates[i] from Dates[i] (year, month, day) on.
+// Applied is the number of steps on or before the date the coupon is read at, set by the prover.
+type StepCoupon struct {
+	Dates   [maxSteps][3]frontend.Variable
+	Rates   [maxSteps]frontend.Variable
+	Applied frontend.Variable
+}
+
+// packDate returns year*10000 + month*100 + day, which keeps the order of the dates
+func packDate(cs *frontend.ConstraintSystem, date [3]frontend.Variable) frontend.Variable {
+	return cs.Add(cs.Mul(date[0], 10000), cs.Mul(date[1], 100), date[2])
+}
+
+// mustApply returns the coupon rate applicable on date, initialRate before the first step.
+// It checks the schedule is the one hashed into scheduleHash.
+func (s *StepCoupon) mustApply(cs *frontend.ConstraintSystem, curveID ecc.ID, hash mimc.MiMC,
+	scheduleHash, initialRate frontend.Variable, date [3]frontend.Variable) frontend.Variable {
+
+	schedule := make([]frontend.Variable, 0, 4*maxSteps)
+	for i := 0; i < maxSteps; i++ {
+		schedule = append(schedule, s.Dates[i][0], s.Dates[i][1], s.Dates[i][2], s.Rates[i])
+	}
+	cs.AssertIsEqual(scheduleHash, hash.Hash(cs, schedule...))
+
+	// rates[k] applies from lower[k] included to upper[k] excluded
+	rates := []frontend.Variable{initialRate}
+	lower := []frontend.Variable{cs.Constant(0)}
+	upper := make([]frontend.Variable, 0, maxSteps+1)
+	for i := 0; i < maxSteps; i++ {
+		packed := packDate(cs, s.Dates[i])
+		if i > 0 {
+			cs.AssertIsLessOrEqual(lower[i], packed)
+		}
+		rates = append(rates, s.Rates[i])
+		lower = append(lower, packed)
+		upper = append(upper, packed)
+	}
+	upper = append(upper, cs.Constant(maxYear*10000+1231+1))
+
+	packed := packDate(cs, date)
+	cs.AssertIsLessOrEqual(selectByIndex(cs, curveID, s.Applied, lower), packed)
+	cs.AssertIsLessOrEqual(cs.Add(packed, 1), selectByIndex(cs, curveID, s.Applied, upper))
+
+	return selectByIndex(cs, curveID, s.Applied, rates)
+}
+
+// stepFields returns the steps as field elements (year, month, day, rate), padded to maxSteps
+func stepFields(steps bondmath.StepSchedule) ([]*big.Int, error) {
+	if len(steps) > maxSteps {
+		return nil, errTooManySteps
+	}
+	fields := make([]*big.Int, 0, 4*maxSteps)
+	for i := 0; i < maxSteps; i++ {
+		date, rate := lastDate, decimal.Zero
+		if i < len(steps) {
+			date, rate = steps[i].Date, steps[i].Rate.Shift(rateDecimals)
+			if !rate.Equal(rate.Truncate(0)) || rate.Sign() <= 0 {
+				return nil, errInvalidStep
+			}
+			if i > 0 && bondmath.DayNumber(date) < bondmath.DayNumber(steps[i-1].Date) {
+				return nil, errStepsNotSorted
+			}
+		}
+		fields = append(fields, big.NewInt(int64(date.Year())), big.NewInt(int64(date.Month())),
+			big.NewInt(int64(date.Day())), rate.BigInt())
+	}
+	return fields, nil
+}
+
+// StepScheduleHash returns the MiMC hash of the coupon steps, padded to maxSteps
+func (bond *Bond) StepScheduleHash() ([]byte, error) {
+	fields, err := stepFields(bond.Coupon.Steps)
+	if err != nil {
+		return nil, err
+	}
+	return hashFields(fields...), nil
+}
+
+// Assign sets the witness values to read the coupon rate of coupon on date
+func (s *StepCoupon) Assign(coupon *bondmath.Coupon, date time.Time) error {
+	fields, err := stepFields(coupon.Steps)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < maxSteps; i++ {
+		for j := 0; j < 3; j++ {
+			s.Dates[i][j].Assign(fields[4*i+j])
+		}
+		s.Rates[i].Assign(fields[4*i+3])
+	}
+
+	applied := 0
+	for _, step := range coupon.Steps {
+		if bondmath.DayNumber(step.Date) <= bondmath.DayNumber(date) {
+			applied++
+		}
+	}
+	s.Applied.Assign(applied)
+	return nil
+}
@@ -0,0 +1,99 @@
+package financial
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"bloconuts/v0/bondmath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/shopspring/decimal"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+type stepCouponCircuit struct {
+	StepScheduleHash frontend.Variable    `gnark:",public"`
+	Date             [3]frontend.Variable `gnark:",public"`
+	Rate             frontend.Variable    `gnark:",public"`
+	InitialRate      frontend.Variable    `gnark:",private"`
+	Steps            StepCoupon           `gnark:",private"`
+}
+
+func (circuit *stepCouponCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+	rate := circuit.Steps.mustApply(cs, curveID, mimc, circuit.StepScheduleHash, circuit.InitialRate, circuit.Date)
+	cs.AssertIsEqual(circuit.Rate, rate)
+	return nil
+}
+
+func TestStepCouponCircuit(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	var circuit stepCouponCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	bond := &Bond{
+		Isin:   "US48128GT919",
+		Size:   "450000",
+		Ticker: "JPM  STEP 06/23/2030 Callable Step 06/23/2026 @ 2.25",
+		Type:   StepUp,
+		Coupon: bondmath.Coupon{
+			Rate:      decimal.RequireFromString("1.5"),
+			Frequency: 2,
+			DayCount:  bondmath.Thirty360,
+			Maturity:  time.Date(2030, 6, 23, 0, 0, 0, 0, time.UTC),
+			Steps: bondmath.StepSchedule{
+				{Date: time.Date(2026, 6, 23, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("2.25")},
+				{Date: time.Date(2028, 6, 23, 0, 0, 0, 0, time.UTC), Rate: decimal.RequireFromString("3")},
+			},
+		},
+	}
+	scheduleHash, err := bond.StepScheduleHash()
+	assert.NoError(err)
+
+	witness := func(date time.Time, rate string) *stepCouponCircuit {
+		var w stepCouponCircuit
+		w.StepScheduleHash.Assign(scheduleHash)
+		assignDate(&w.Date, date)
+		w.Rate.Assign(decimal.RequireFromString(rate).Shift(rateDecimals).BigInt())
+		w.InitialRate.Assign(bond.Coupon.Rate.Shift(rateDecimals).BigInt())
+		assert.NoError(w.Steps.Assign(&bond.Coupon, date))
+		return &w
+	}
+
+	dates := []time.Time{
+		time.Date(2021, 11, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 6, 22, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 6, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2028, 6, 23, 0, 0, 0, 0, time.UTC),
+		time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, date := range dates {
+		rate := bond.Coupon.RateOn(date).String()
+		assert.SolvingSucceeded(r1cs, witness(date, rate))
+		assert.SolvingFailed(r1cs, witness(date, "2.5"))
+	}
+
+	// the prover can't apply a step early
+	early := witness(dates[1], "2.25")
+	early.Steps.Applied = frontend.Variable{}
+	early.Steps.Applied.Assign(1)
+	assert.SolvingFailed(r1cs, early)
+
+	// nor use another schedule than the committed one
+	other := witness(dates[0], "1.5")
+	other.StepScheduleHash = frontend.Variable{}
+	other.StepScheduleHash.Assign(hashFields(big.NewInt(1)))
+	assert.SolvingFailed(r1cs, other)
+
+	// steps must be in date order
+	bond.Coupon.Steps[0], bond.Coupon.Steps[1] = bond.Coupon.Steps[1], bond.Coupon.Steps[0]
+	_, err = bond.StepScheduleHash()
+	assert.Error(err)
+}
@@ -1,13 +1,12 @@
 package financial
 
 import (
-	"bytes"
-	"encoding/json"
-
-	"github.com/consensys/gnark-crypto/hash"
 	"github.com/shopspring/decimal"
 )
 
+// testSecurityMaster lists the bonds of the test cases
+const testSecurityMaster = "testdata/bonds.json"
+
 // Represent a test case
 type TestCase struct {
 	quoteCpt1       []byte
@@ -15,29 +14,25 @@ type TestCase struct {
 	quoteCpt3       []byte
 	acceptedQuote   []byte
 	bondHash        []byte
+	bond            *Bond
 	quoteNumberCpt1 string
 	quoteNumberCpt2 string
 	quoteNumberCpt3 string
 	message         string
-}
-
-// Bond has an Isin, size and ticker
-type Bond struct {
-	Isin   string
-	Size   string
-	Ticker string
+	proofFails      bool // the initiator can't prove the trade
 }
 
 func createTestCases() [16]TestCase {
 
 	toRet := [16]TestCase{}
 
-	bond := &Bond{
-		Isin:   "CA29250NAT24",
-		Size:   "550000",
-		Ticker: "ENB 5.375 27-Sep-2027",
+	master, err := LoadSecurityMaster(testSecurityMaster)
+	if err != nil {
+		panic(err)
 	}
 
+	bond := lookupTestBond(master, "CA29250NAT24")
+
 	// TODO - Cpt1 Quote is always the acepted quote, see how to change that
 	// 2nd parameter is always the accepted quote
 	toRet[0] = getQuotesValue(bond, "92.63", "92.63", "95", "Initiator Party selected Cpt1")                               // test case 1 - 2 quotes have same value.
@@ -46,51 +41,44 @@ func createTestCases() [16]TestCase {
 	toRet[3] = getQuotesValue(bond, "92.63", "92.63", "92.63", "Initiator Party selected Cpt1")                            // test case 2
 	toRet[4] = getQuotesValue(bond, "97.63", "94.63", "95.63", "Generate proof fails - Initiator selected a higher quote") // test case 8
 	toRet[5] = getQuotesValue(bond, "-97.63", "-94.63", "-95.63", "Generate proof fails - Negative quotes")                // test case 11
-
-	bond = &Bond{
-		Isin:   "CA29250NAT25",
-		Size:   "1550000",
-		Ticker: "ENB 1.375 10-Sep-2025",
+	for _, i := range []int{2, 4, 5} {
+		toRet[i].proofFails = true
 	}
 
+	bond = lookupTestBond(master, "CA29250NAS41")
+
 	toRet[6] = getQuotesValue(bond, "91.63", "92.63", "95.63", "Initiator Party selects the smallest quote")
 	toRet[7] = getQuotesValue(bond, "93", "98", "94", "Initiator Party selects the smallest integer quote")
 
 	//test cases for exotic bonds, callable bond, step up, FRN. need to update quotes
-	bond = &Bond{
-		Isin:   "6625HKC3",
-		Size:   "550000",
-		Ticker: "JPM 3.125% 01/23/2025 Callable",
-	}
+	bond = lookupTestBond(master, "US46625HKC33")
 	toRet[8] = getQuotesValue(bond, "91.63", "92.63", "95.63", "Initiator Party selects the smallest quote")
 	toRet[9] = getQuotesValue(bond, "93", "98", "94", "Initiator Party selects the smallest integer quote")
 	// Callable with a step up on 6/23/26
-	bond = &Bond{
-		Isin:   "8128GT91",
-		Size:   "450000",
-		Ticker: "JPM  STEP 06/23/2030 Callable Step 06/23/2026 @ 2.25",
-	}
+	bond = lookupTestBond(master, "US48128GT919")
 	toRet[10] = getQuotesValue(bond, "91.63", "92.63", "95.63", "Initiator Party selects the smallest quote")
 	toRet[11] = getQuotesValue(bond, "93", "98", "94", "Initiator Party selects the smallest integer quote")
 	// FRN/Variable rate bond
-	bond = &Bond{
-		Isin:   "89114QCR7",
-		Size:   "600000",
-		Ticker: "The Toronto-Dominion VAR 03/04/2024",
-	}
+	bond = lookupTestBond(master, "US89114QCR74")
 	toRet[12] = getQuotesValue(bond, "91.63", "92.63", "95.63", "Initiator Party selects the smallest quote")
 	toRet[13] = getQuotesValue(bond, "93", "98", "94", "Initiator Party selects the smallest integer quote")
-	bond = &Bond{
-		Isin:   "46625HKC3",
-		Size:   "625000",
-		Ticker: "JPM 3.125% 01/23/2025 Callable",
-	}
+	bond = lookupTestBond(master, "US46625HKC33")
+	bond.Size = "625000"
 	toRet[14] = getQuotesValue(bond, "91.63", "92.63", "95.63", "Initiator Party selects the smallest quote")
 	toRet[15] = getQuotesValue(bond, "93", "98", "94", "Initiator Party selects the smallest integer quote")
 
 	return toRet
 }
 
+// lookupTestBond returns the bond with that ISIN in the security master
+func lookupTestBond(master SecurityMaster, isin string) *Bond {
+	bond, err := master.Lookup(isin)
+	if err != nil {
+		panic(err)
+	}
+	return bond
+}
+
 func getQuotesValue(bond *Bond, quoteCpt1, quoteCpt2, quoteCpt3, message string) TestCase {
 
 	_quoteCpt1, err := decimal.NewFromString(quoteCpt1)
@@ -129,15 +117,12 @@ func getQuotesValue(bond *Bond, quoteCpt1, quoteCpt2, quoteCpt3, message string)
 
 	testCase.acceptedQuote = _quoteCpt1.BigInt().Bytes()
 
-	reqBodyBytes := new(bytes.Buffer)
-	json.NewEncoder(reqBodyBytes).Encode(bond)
-
-	hashFunc := hash.MIMC_BN254
-
-	goMimc := hashFunc.New("seed")
-	goMimc.Write([]byte(reqBodyBytes.Bytes()))
-	var IsinHash = goMimc.Sum(nil)
+	IsinHash, err := bond.Hash()
+	if err != nil {
+		panic(err)
+	}
 	testCase.bondHash = IsinHash
+	testCase.bond = bond
 
 	testCase.message = message
 	return testCase
@@ -0,0 +1,138 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/signature/eddsa"
+)
+
+// Side of a two-way quote the initiator trades on, public input of twoWayCircuit
+const (
+	Buy  = 0 // the initiator buys at the offer of the dealer
+	Sell = 1 // the initiator sells at the bid of the dealer
+)
+
+var errCrossedQuote = errors.New("bid must not be above the offer")
+
+// TwoWayQuote is the market of a dealer for a bond: the bid it buys at and the offer it sells at,
+// both clean price * size in cents, signed together
+type TwoWayQuote struct {
+	Bid       *big.Int
+	Offer     *big.Int
+	Signature []byte // Sign(MiMC(bond hash, RFQ ID, bid, offer))
+}
+
+// twoWayQuoteHash is the message of a two-way quote signed for a bond in a RFQ, checked in twoWayCircuit
+func twoWayQuoteHash(bondHash []byte, rfqID, bid, offer *big.Int) []byte {
+	return hashFields(new(big.Int).SetBytes(bondHash), rfqID, bid, offer)
+}
+
+// SignTwoWayQuote signs the bid and offer of a dealer for the bond with that hash, in the RFQ with that ID
+func SignTwoWayQuote(key signature.Signer, bondHash []byte, rfqID, bid, offer *big.Int) (*TwoWayQuote, error) {
+	for _, v := range []*big.Int{bid, offer} {
+		if v.Sign() <= 0 || v.Cmp(maxQuote) >= 0 {
+			return nil, errMalformedQuote
+		}
+	}
+	if bid.Cmp(offer) > 0 {
+		return nil, errCrossedQuote
+	}
+	sig, err := key.Sign(twoWayQuoteHash(bondHash, rfqID, bid, offer), hash.MIMC_BN254.New("seed"))
+	if err != nil {
+		return nil, err
+	}
+	return &TwoWayQuote{Bid: new(big.Int).Set(bid), Offer: new(big.Int).Set(offer), Signature: sig}, nil
+}
+
+// Verify checks the quote is signed by the dealer for the bond in the RFQ with that ID
+func (q *TwoWayQuote) Verify(dealer signature.PublicKey, bondHash []byte, rfqID *big.Int) bool {
+	return verifySignature(dealer, q.Signature, twoWayQuoteHash(bondHash, rfqID, q.Bid, q.Offer))
+}
+
+// Price is the side of the quote the initiator trades on: the offer when it buys, the bid when it sells
+func (q *TwoWayQuote) Price(side int) *big.Int {
+	if side == Sell {
+		return q.Bid
+	}
+	return q.Offer
+}
+
+// BestBidOffer returns the highest bid and the lowest offer of the quotes
+func BestBidOffer(quotes []*TwoWayQuote) (bid, offer *big.Int) {
+	for _, q := range quotes {
+		if bid == nil || q.Bid.Cmp(bid) > 0 {
+			bid = q.Bid
+		}
+		if offer == nil || q.Offer.Cmp(offer) < 0 {
+			offer = q.Offer
+		}
+	}
+	return bid, offer
+}
+
+// twoWayCircuit is the RFQ variant where dealers answer with a bid and an offer. The public Side selects
+// the side the initiator trades on: it buys at the smallest offer or sells at the largest bid. With the
+// public CheckBBO flag set, it also proves ExecutionPrice, for instance the mid of a negotiated trade,
+// lies within the best bid and the best offer of the dealers.
+type twoWayCircuit struct {
+	Bond           frontend.Variable    `gnark:",public"`  // hash of the bond attributes
+	RFQID          frontend.Variable    `gnark:",public"`  // unique to the RFQ, signed with the quotes
+	PublicKeyCpts  [3]PublicKey         `gnark:",public"`  // counterparties asked for a quote
+	Side           frontend.Variable    `gnark:",public"`  // Buy or Sell
+	AcceptedQuote  frontend.Variable    `gnark:",public"`  // offer or bid accepted on that side
+	CheckBBO       frontend.Variable    `gnark:",public"`  // 1 to check ExecutionPrice against the best bid/offer
+	ExecutionPrice frontend.Variable    `gnark:",public"`  // checked only with CheckBBO
+	Bids           [3]frontend.Variable `gnark:",private"` // clean price * size in cents
+	Offers         [3]frontend.Variable `gnark:",private"` // clean price * size in cents
+	TwoWaySigned   [3]Signature         `gnark:",private"` // Sign(Bond hash, RFQID, bid, offer)
+	AcceptedIndex  frontend.Variable    `gnark:",private"` // slot of the accepted quote
+}
+
+func (circuit *twoWayCircuit) Define(curveID ecc.ID, cs *frontend.ConstraintSystem) error {
+
+	params, err := twistededwards.NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	mimc, _ := mimc.NewMiMC("seed", curveID)
+
+	cs.AssertIsBoolean(circuit.Side)
+	cs.AssertIsBoolean(circuit.CheckBBO)
+
+	var prices [3]frontend.Variable
+	for i := range circuit.Bids {
+		// a market isn't crossed
+		cs.AssertIsEqual(cs.IsZero(circuit.Bids[i], curveID), 0)
+		cs.AssertIsLessOrEqual(circuit.Bids[i], circuit.Offers[i])
+
+		// bid and offer are signed together, for that bond in this RFQ
+		circuit.PublicKeyCpts[i].Curve = params
+		message := mimc.Hash(cs, circuit.Bond, circuit.RFQID, circuit.Bids[i], circuit.Offers[i])
+		mustBeCanonical(cs, circuit.TwoWaySigned[i])
+		eddsa.Verify(cs, circuit.TwoWaySigned[i], message, circuit.PublicKeyCpts[i])
+
+		prices[i] = cs.Select(circuit.Side, circuit.Bids[i], circuit.Offers[i])
+	}
+
+	// the accepted quote is the best one on its side: the smallest offer, or the largest bid
+	cs.AssertIsEqual(circuit.AcceptedQuote, selectByIndex(cs, curveID, circuit.AcceptedIndex, prices[:]))
+	for i := range prices {
+		cs.AssertIsLessOrEqual(cs.Select(circuit.Side, prices[i], circuit.AcceptedQuote),
+			cs.Select(circuit.Side, circuit.AcceptedQuote, prices[i]))
+	}
+
+	// best bid <= execution price <= best offer
+	for i := range circuit.Bids {
+		cs.AssertIsLessOrEqual(cs.Select(circuit.CheckBBO, circuit.Bids[i], 0), circuit.ExecutionPrice)
+		cs.AssertIsLessOrEqual(circuit.ExecutionPrice, cs.Select(circuit.CheckBBO, circuit.Offers[i], circuit.ExecutionPrice))
+	}
+
+	return nil
+}
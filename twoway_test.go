@@ -0,0 +1,97 @@
+package financial
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+func TestTwoWayQuote(t *testing.T) {
+	assert := groth16.NewAssert(t)
+
+	f := newRFQFixture("CA29250NAT24")
+	rfqID := big.NewInt(1)
+
+	_, err := SignTwoWayQuote(f.keys[0], f.bondHash, rfqID, big.NewInt(51000000), big.NewInt(50946500))
+	assert.True(errors.Is(err, errCrossedQuote), err)
+
+	// best bid 50800000 from the second dealer, best offer 51050000 from the third
+	var quotes [3]*TwoWayQuote
+	for i, market := range [][2]int64{{50500000, 51100000}, {50800000, 51200000}, {50700000, 51050000}} {
+		quotes[i], err = SignTwoWayQuote(f.keys[i], f.bondHash, rfqID, big.NewInt(market[0]), big.NewInt(market[1]))
+		assert.NoError(err)
+		assert.True(quotes[i].Verify(f.keys[i].Public(), f.bondHash, rfqID))
+		assert.False(quotes[i].Verify(f.keys[i].Public(), f.bondHash, big.NewInt(2)))
+	}
+	bid, offer := BestBidOffer(quotes[:])
+	assert.Equal(big.NewInt(50800000), bid)
+	assert.Equal(big.NewInt(51050000), offer)
+	assert.Equal(offer, quotes[2].Price(Buy))
+	assert.Equal(bid, quotes[1].Price(Sell))
+
+	var circuit twoWayCircuit
+	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	assert.NoError(err)
+
+	witness := func(side, index int, execution *big.Int, quotes [3]*TwoWayQuote) *twoWayCircuit {
+		var w twoWayCircuit
+		w.Bond.Assign(f.bondHash)
+		w.RFQID.Assign(rfqID)
+		f.assignKeys(&w.PublicKeyCpts)
+		for i, quote := range quotes {
+			w.Bids[i].Assign(quote.Bid)
+			w.Offers[i].Assign(quote.Offer)
+			assert.NoError(assignSignature(&w.TwoWaySigned[i], quote.Signature))
+		}
+		w.Side.Assign(side)
+		w.AcceptedIndex.Assign(index)
+		w.AcceptedQuote.Assign(quotes[index].Price(side))
+		if execution != nil {
+			w.CheckBBO.Assign(1)
+			w.ExecutionPrice.Assign(execution)
+		} else {
+			w.CheckBBO.Assign(0)
+			w.ExecutionPrice.Assign(0)
+		}
+		return &w
+	}
+
+	// buying lifts the smallest offer, selling hits the largest bid
+	assert.SolvingSucceeded(r1cs, witness(Buy, 2, nil, quotes))
+	assert.SolvingSucceeded(r1cs, witness(Sell, 1, nil, quotes))
+	assert.SolvingFailed(r1cs, witness(Buy, 1, nil, quotes))
+	assert.SolvingFailed(r1cs, witness(Sell, 2, nil, quotes))
+
+	// the side is public: a seller can't be proven to have bought at a bid
+	bad := witness(Sell, 1, nil, quotes)
+	bad.Side = frontend.Variable{}
+	bad.Side.Assign(Buy)
+	assert.SolvingFailed(r1cs, bad)
+
+	// and the quotes are the ones signed for this RFQ
+	bad = witness(Buy, 2, nil, quotes)
+	bad.RFQID = frontend.Variable{}
+	bad.RFQID.Assign(2)
+	assert.SolvingFailed(r1cs, bad)
+
+	// an execution at the mid, or at the touch, is within the best bid/offer
+	mid := new(big.Int).Add(bid, offer)
+	mid.Rsh(mid, 1)
+	assert.SolvingSucceeded(r1cs, witness(Buy, 2, mid, quotes))
+	assert.SolvingSucceeded(r1cs, witness(Sell, 1, bid, quotes))
+	assert.SolvingFailed(r1cs, witness(Buy, 2, new(big.Int).Add(offer, big.NewInt(1)), quotes))
+	assert.SolvingFailed(r1cs, witness(Sell, 1, new(big.Int).Sub(bid, big.NewInt(1)), quotes))
+
+	// a crossed market can't be used, even signed
+	crossed := &TwoWayQuote{Bid: big.NewInt(51300000), Offer: big.NewInt(51000000)}
+	crossed.Signature, err = f.keys[0].Sign(twoWayQuoteHash(f.bondHash, rfqID, crossed.Bid, crossed.Offer), hash.MIMC_BN254.New("seed"))
+	assert.NoError(err)
+	assert.SolvingFailed(r1cs, witness(Sell, 0, nil, [3]*TwoWayQuote{crossed, quotes[1], quotes[2]}))
+}